@@ -0,0 +1,222 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jimeh/evm/manager"
+	"github.com/jimeh/go-render"
+	"github.com/spf13/cobra"
+)
+
+func NewBench(mgr *manager.Manager) (*cobra.Command, error) {
+	cmd := &cobra.Command{
+		Use:   "bench [flags] [-- <emacs-args>...]",
+		Short: "Benchmark Emacs startup with each of multiple versions",
+		Long: `Repeatedly run Emacs with each selected version, and report wall time
+and peak memory usage. Versions are benchmarked one at a time.
+
+By default "emacs -Q --batch --eval '(kill-emacs)'" is timed. Use --init or
+--eval to time loading an init file or evaluating an elisp expression, or
+give Emacs arguments after "--" to time those instead.`,
+		SilenceUsage:      true,
+		ValidArgsFunction: noValidArgs,
+		RunE:              benchRunE(mgr),
+	}
+
+	addMatrixFlags(cmd, mgr)
+	cmd.Flags().IntP(
+		"runs", "n", 10, "number of timed runs per version",
+	)
+	cmd.Flags().String(
+		"init", "", "init file to load in each run",
+	)
+	cmd.Flags().StringP(
+		"eval", "e", "", "elisp expression to evaluate in each run",
+	)
+	cmd.Flags().StringP(
+		"format", "f", "text", "output format, \"text\", \"yaml\", or \"json\"",
+	)
+
+	return cmd, nil
+}
+
+type benchVersion struct {
+	Version string `yaml:"version" json:"version"`
+	Runs    int    `yaml:"runs" json:"runs"`
+	Error   string `yaml:"error,omitempty" json:"error,omitempty"`
+
+	// Wall times in seconds, and peak resident set size in bytes.
+	Min    float64   `yaml:"min" json:"min"`
+	Median float64   `yaml:"median" json:"median"`
+	P95    float64   `yaml:"p95" json:"p95"`
+	MaxRSS int64     `yaml:"max_rss" json:"max_rss"`
+	Times  []float64 `yaml:"times" json:"times"`
+}
+
+type benchOutput struct {
+	Args     []string        `yaml:"args" json:"args"`
+	Versions []*benchVersion `yaml:"versions" json:"versions"`
+}
+
+func (bo *benchOutput) String() string {
+	var buf strings.Builder
+
+	tw := tabwriter.NewWriter(&buf, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "VERSION\tRUNS\tMIN\tMEDIAN\tP95\tMAX RSS")
+	for _, ver := range bo.Versions {
+		if ver.Error != "" {
+			fmt.Fprintf(tw, "%s\t%d\terror\t\t\t\n", ver.Version, ver.Runs)
+			continue
+		}
+
+		fmt.Fprintf(
+			tw, "%s\t%d\t%s\t%s\t%s\t%.1f MB\n",
+			ver.Version, ver.Runs,
+			benchDuration(ver.Min),
+			benchDuration(ver.Median),
+			benchDuration(ver.P95),
+			float64(ver.MaxRSS)/(1024*1024),
+		)
+	}
+	_ = tw.Flush()
+
+	for _, ver := range bo.Versions {
+		if ver.Error != "" {
+			fmt.Fprintf(&buf, "\nEmacs %s: %s\n", ver.Version, ver.Error)
+		}
+	}
+
+	return buf.String()
+}
+
+func benchDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second)).Round(
+		100 * time.Microsecond,
+	)
+}
+
+func benchRunE(mgr *manager.Manager) runEFunc {
+	return func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		format := flagString(cmd, "format")
+
+		runs, _ := cmd.Flags().GetInt("runs")
+		if runs < 1 {
+			return errors.New("--runs must be at least 1.")
+		}
+
+		init := flagString(cmd, "init")
+		eval := flagString(cmd, "eval")
+		if len(args) > 0 && (init != "" || eval != "") {
+			return errors.New(
+				"--init and --eval cannot be used with Emacs arguments.",
+			)
+		}
+
+		versions, err := matrixVersions(cmd, mgr)
+		if err != nil {
+			return err
+		}
+
+		emacsArgs := args
+		if len(emacsArgs) == 0 {
+			emacsArgs = []string{"-Q", "--batch"}
+			if init != "" {
+				emacsArgs = append(emacsArgs, "-l", init)
+			}
+			if eval != "" {
+				emacsArgs = append(emacsArgs, "--eval", eval)
+			}
+			emacsArgs = append(emacsArgs, "--eval", "(kill-emacs)")
+		}
+
+		output := &benchOutput{Args: emacsArgs}
+		failed := 0
+		for _, version := range versions {
+			ver := benchVersionRun(ctx, mgr, version, emacsArgs, runs)
+			if ver.Error != "" {
+				failed++
+			}
+			output.Versions = append(output.Versions, ver)
+
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+		}
+
+		err = render.Pretty(cmd.OutOrStdout(), format, output)
+		if err != nil {
+			return err
+		}
+
+		if failed > 0 {
+			return fmt.Errorf(
+				"Benchmark failed for %d of %d versions.",
+				failed, len(versions),
+			)
+		}
+
+		return nil
+	}
+}
+
+func benchVersionRun(
+	ctx context.Context,
+	mgr *manager.Manager,
+	version string,
+	args []string,
+	runs int,
+) *benchVersion {
+	ver := &benchVersion{Version: version}
+
+	for i := 0; i < runs; i++ {
+		c, err := mgr.Command(ctx, version, "emacs", args)
+		if err != nil {
+			ver.Error = err.Error()
+			return ver
+		}
+
+		output, code, duration, err := runCaptured(c)
+		if err == nil && code != 0 {
+			err = fmt.Errorf(
+				"Emacs exited with status %d: %s",
+				code, strings.TrimSpace(string(output)),
+			)
+		}
+		if err != nil {
+			ver.Error = err.Error()
+			return ver
+		}
+
+		ver.Runs++
+		ver.Times = append(ver.Times, duration.Seconds())
+		if rss := maxRSS(c); rss > ver.MaxRSS {
+			ver.MaxRSS = rss
+		}
+	}
+
+	sorted := append([]float64{}, ver.Times...)
+	sort.Float64s(sorted)
+	ver.Min = sorted[0]
+	ver.Median = percentile(sorted, 50)
+	ver.P95 = percentile(sorted, 95)
+
+	return ver
+}
+
+// Returns the nearest-rank percentile p of sorted values.
+func percentile(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+
+	return sorted[rank-1]
+}
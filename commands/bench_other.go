@@ -0,0 +1,10 @@
+//go:build !unix
+
+package commands
+
+import "os/exec"
+
+// Peak memory usage is not available on this platform.
+func maxRSS(_ *exec.Cmd) int64 {
+	return 0
+}
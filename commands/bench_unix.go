@@ -0,0 +1,28 @@
+//go:build unix
+
+package commands
+
+import (
+	"os/exec"
+	"runtime"
+	"syscall"
+)
+
+// Returns the peak resident set size of a finished command in bytes.
+func maxRSS(c *exec.Cmd) int64 {
+	if c.ProcessState == nil {
+		return 0
+	}
+
+	rusage, ok := c.ProcessState.SysUsage().(*syscall.Rusage)
+	if !ok {
+		return 0
+	}
+
+	// Linux reports kilobytes, macOS reports bytes.
+	if runtime.GOOS == "darwin" {
+		return int64(rusage.Maxrss)
+	}
+
+	return int64(rusage.Maxrss) * 1024
+}
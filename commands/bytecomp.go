@@ -0,0 +1,73 @@
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var bytecompWarningRegexp = regexp.MustCompile(
+	`^(.+?):(?:(\d+):(?:(\d+):)?)?\s*(Warning|Error):\s*(.*)$`,
+)
+
+type compileWarning struct {
+	File     string `yaml:"file" json:"file"`
+	Line     int    `yaml:"line" json:"line"`
+	Column   int    `yaml:"column" json:"column"`
+	Severity string `yaml:"severity" json:"severity"`
+	Message  string `yaml:"message" json:"message"`
+}
+
+// Key used to match the same warning across versions, as line and column
+// numbers and quote styles vary between versions.
+func (cw *compileWarning) key() string {
+	return cw.File + "\x00" + normalizeQuotes(cw.Message)
+}
+
+// Parses byte-compiler output into warnings and errors. Messages wrapped over
+// multiple indented lines are joined.
+func parseBytecompOutput(output []byte) []*compileWarning {
+	var warnings []*compileWarning
+	var last *compileWarning
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if m := bytecompWarningRegexp.FindStringSubmatch(line); m != nil {
+			last = &compileWarning{
+				File:     m[1],
+				Severity: strings.ToLower(m[4]),
+				Message:  strings.TrimSpace(m[5]),
+			}
+			last.Line, _ = strconv.Atoi(m[2])
+			last.Column, _ = strconv.Atoi(m[3])
+			warnings = append(warnings, last)
+
+			continue
+		}
+
+		if last != nil && strings.HasPrefix(line, " ") &&
+			strings.TrimSpace(line) != "" {
+			last.Message += " " + strings.TrimSpace(line)
+
+			continue
+		}
+
+		last = nil
+	}
+
+	return warnings
+}
+
+func normalizeQuotes(s string) string {
+	return strings.NewReplacer("‘", "'", "’", "'", "`", "'").Replace(s)
+}
+
+// Quotes s as an elisp string literal.
+func elispString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
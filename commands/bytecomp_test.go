@@ -0,0 +1,171 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseBytecompOutput(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []*compileWarning
+	}{
+		{
+			name:   "no warnings",
+			output: "Compiling foo.el...\nWrote foo.elc\n",
+			want:   nil,
+		},
+		{
+			name: "Emacs 27 format",
+			output: "\nIn toplevel form:\n" +
+				"foo.el:20:3:Warning: reference to free variable `x'\n" +
+				"foo.el:30:1:Error: Wrong number of arguments\n",
+			want: []*compileWarning{
+				{
+					File:     "foo.el",
+					Line:     20,
+					Column:   3,
+					Severity: "warning",
+					Message:  "reference to free variable `x'",
+				},
+				{
+					File:     "foo.el",
+					Line:     30,
+					Column:   1,
+					Severity: "error",
+					Message:  "Wrong number of arguments",
+				},
+			},
+		},
+		{
+			name: "Emacs 28 and later format",
+			output: "\nIn toplevel form:\n" +
+				"foo.el:20:1: Warning: reference to free variable ‘x’\n" +
+				"\nIn end of data:\n" +
+				"lisp/bar.el:40:1: Warning: the function ‘baz’ is not " +
+				"known to be defined.\n",
+			want: []*compileWarning{
+				{
+					File:     "foo.el",
+					Line:     20,
+					Column:   1,
+					Severity: "warning",
+					Message:  "reference to free variable ‘x’",
+				},
+				{
+					File:     "lisp/bar.el",
+					Line:     40,
+					Column:   1,
+					Severity: "warning",
+					Message: "the function ‘baz’ is not known to be " +
+						"defined.",
+				},
+			},
+		},
+		{
+			name: "wrapped lines",
+			output: "\nIn foo-fn:\n" +
+				"foo.el:12:5: Warning: ‘bar’ is an obsolete function " +
+				"(as of 28.1); use\n" +
+				"    ‘baz’ instead.\n" +
+				"foo.el:14:5: Warning: the following functions are not " +
+				"known to be defined:\n" +
+				"    qux, quux\n" +
+				"\n" +
+				"  indented line after a blank line\n",
+			want: []*compileWarning{
+				{
+					File:     "foo.el",
+					Line:     12,
+					Column:   5,
+					Severity: "warning",
+					Message: "‘bar’ is an obsolete function (as of 28.1); " +
+						"use ‘baz’ instead.",
+				},
+				{
+					File:     "foo.el",
+					Line:     14,
+					Column:   5,
+					Severity: "warning",
+					Message: "the following functions are not known to " +
+						"be defined: qux, quux",
+				},
+			},
+		},
+		{
+			name:   "without line or column",
+			output: "foo.el: Warning: cannot open file\n",
+			want: []*compileWarning{
+				{
+					File:     "foo.el",
+					Severity: "warning",
+					Message:  "cannot open file",
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseBytecompOutput([]byte(tt.output))
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("parseBytecompOutput() returned %d warnings, "+
+					"want %d: %#v", len(got), len(tt.want), got)
+			}
+			for i := range got {
+				if !reflect.DeepEqual(got[i], tt.want[i]) {
+					t.Errorf("parseBytecompOutput()[%d] = %#v, want %#v",
+						i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestNewCompileCheckOutput(t *testing.T) {
+	freeVar := func(line, column int, quoted string) *compileWarning {
+		return &compileWarning{
+			File:     "foo.el",
+			Line:     line,
+			Column:   column,
+			Severity: "warning",
+			Message:  "reference to free variable " + quoted,
+		}
+	}
+
+	output := newCompileCheckOutput([]*compileCheckVersion{
+		{
+			Version: "27.2",
+			Warnings: []*compileWarning{
+				freeVar(10, 1, "`x'"),
+				freeVar(20, 1, "`x'"),
+			},
+		},
+		{
+			Version: "29.4",
+			Warnings: []*compileWarning{
+				freeVar(10, 5, "‘x’"),
+				freeVar(20, 5, "‘x’"),
+				freeVar(30, 5, "‘x’"),
+			},
+		},
+	})
+
+	type summary struct {
+		Location string
+		Versions []string
+	}
+	var got []summary
+	for _, w := range output.Warnings {
+		got = append(got, summary{w.location(), w.Versions})
+	}
+	want := []summary{
+		{"foo.el:10:1, 10:5", []string{"27.2", "29.4"}},
+		{"foo.el:20:1, 20:5", []string{"27.2", "29.4"}},
+		{"foo.el:30:5", []string{"29.4"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("warnings = %+v, want %+v", got, want)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"io"
 	"os"
 
+	"github.com/jimeh/evm/manager"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
@@ -20,6 +21,17 @@ func PersistentPreRunE(cmd *cobra.Command, _ []string) error {
 	return nil
 }
 
+func persistentPreRunE(mgr *manager.Manager) runEFunc {
+	return func(cmd *cobra.Command, args []string) error {
+		err := PersistentPreRunE(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		return healShims(cmd, mgr)
+	}
+}
+
 func SetupZerolog(cmd *cobra.Command) error {
 	var levelStr string
 	if v := os.Getenv("EVM_DEBUG"); v != "" {
@@ -74,6 +86,18 @@ func noValidArgs(
 	return nil, cobra.ShellCompDirectiveNoFileComp
 }
 
+func dirValidArgs(
+	_ *cobra.Command,
+	args []string,
+	_ string,
+) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return nil, cobra.ShellCompDirectiveFilterDirs
+}
+
 func flagString(cmd *cobra.Command, name string) string {
 	var r string
 
@@ -0,0 +1,283 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jimeh/evm/manager"
+	"github.com/jimeh/go-render"
+	"github.com/spf13/cobra"
+)
+
+func NewCompileCheck(mgr *manager.Manager) (*cobra.Command, error) {
+	cmd := &cobra.Command{
+		Use:   "compile-check [flags] <file>...",
+		Short: "Byte-compile elisp files with each of multiple Emacs versions",
+		Long: `Byte-compile elisp files in a clean batch Emacs for each selected
+version, and report warnings along with the versions they appear on. Compiled
+files are written to a temporary directory, leaving source directories
+untouched. Exits with a non-zero status if any warnings or errors are found.`,
+		Args:              cobra.MinimumNArgs(1),
+		SilenceUsage:      true,
+		ValidArgsFunction: elispFileValidArgs,
+		RunE:              compileCheckRunE(mgr),
+	}
+
+	addMatrixFlags(cmd, mgr)
+	addJobsFlag(cmd)
+	cmd.Flags().StringArrayP(
+		"directory", "L", []string{"."}, "directory to add to load-path",
+	)
+	cmd.Flags().StringP(
+		"format", "f", "text", "output format, \"text\", \"yaml\", or \"json\"",
+	)
+
+	return cmd, nil
+}
+
+type compileCheckVersion struct {
+	Version  string            `yaml:"version" json:"version"`
+	ExitCode int               `yaml:"exit_code" json:"exit_code"`
+	Error    string            `yaml:"error,omitempty" json:"error,omitempty"`
+	Output   string            `yaml:"-" json:"-"`
+	Warnings []*compileWarning `yaml:"warnings" json:"warnings"`
+}
+
+type compileCheckWarning struct {
+	File      string                  `yaml:"file" json:"file"`
+	Severity  string                  `yaml:"severity" json:"severity"`
+	Message   string                  `yaml:"message" json:"message"`
+	Versions  []string                `yaml:"versions" json:"versions"`
+	Locations []*compileCheckLocation `yaml:"locations" json:"locations"`
+}
+
+type compileCheckLocation struct {
+	Version string `yaml:"version" json:"version"`
+	Line    int    `yaml:"line" json:"line"`
+	Column  int    `yaml:"column" json:"column"`
+}
+
+type compileCheckOutput struct {
+	Versions []*compileCheckVersion `yaml:"versions" json:"versions"`
+	Warnings []*compileCheckWarning `yaml:"warnings" json:"warnings"`
+}
+
+// Groups warnings from all versions, recording which versions each appears
+// on, and where. Repeated warnings within a file are matched across versions
+// by the order they appear in, as line and column numbers vary between
+// versions.
+func newCompileCheckOutput(
+	versions []*compileCheckVersion,
+) *compileCheckOutput {
+	output := &compileCheckOutput{Versions: versions}
+
+	byKey := map[string]*compileCheckWarning{}
+	for _, ver := range versions {
+		seen := map[string]int{}
+		for _, w := range ver.Warnings {
+			key := w.key()
+			seen[key]++
+			key += "\x00" + strconv.Itoa(seen[key])
+
+			cw, ok := byKey[key]
+			if !ok {
+				cw = &compileCheckWarning{
+					File:     w.File,
+					Severity: w.Severity,
+					Message:  w.Message,
+				}
+				byKey[key] = cw
+				output.Warnings = append(output.Warnings, cw)
+			}
+
+			cw.Versions = append(cw.Versions, ver.Version)
+			cw.Locations = append(cw.Locations, &compileCheckLocation{
+				Version: ver.Version,
+				Line:    w.Line,
+				Column:  w.Column,
+			})
+		}
+	}
+
+	sort.SliceStable(output.Warnings, func(i, j int) bool {
+		a, b := output.Warnings[i], output.Warnings[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+
+		return a.Locations[0].Line < b.Locations[0].Line
+	})
+
+	return output
+}
+
+// Returns the warning's file and each distinct line and column it was
+// reported at, like "foo.el:12:5, 12:1".
+func (ccw *compileCheckWarning) location() string {
+	var positions []string
+	for _, loc := range ccw.Locations {
+		pos := fmt.Sprintf("%d:%d", loc.Line, loc.Column)
+		if !stringsContains(positions, pos) {
+			positions = append(positions, pos)
+		}
+	}
+
+	return ccw.File + ":" + strings.Join(positions, ", ")
+}
+
+func (cco *compileCheckOutput) String() string {
+	var buf strings.Builder
+
+	if len(cco.Warnings) == 0 {
+		buf.WriteString("No warnings.\n")
+	} else {
+		tw := tabwriter.NewWriter(&buf, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "VERSIONS\tLOCATION\tMESSAGE")
+		for _, w := range cco.Warnings {
+			versions := strings.Join(w.Versions, ", ")
+			if len(w.Versions) == len(cco.Versions) {
+				versions = "all"
+			}
+
+			location := w.location()
+			message := w.Message
+			if w.Severity != "warning" {
+				message = strings.ToUpper(w.Severity) + ": " + message
+			}
+
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", versions, location, message)
+		}
+		_ = tw.Flush()
+	}
+
+	for _, ver := range cco.Versions {
+		if ver.Error == "" {
+			continue
+		}
+
+		fmt.Fprintf(&buf, "\nEmacs %s: %s\n", ver.Version, ver.Error)
+		if ver.Output != "" {
+			fmt.Fprintf(&buf, "  %s\n", strings.ReplaceAll(
+				strings.TrimRight(ver.Output, "\n"), "\n", "\n  ",
+			))
+		}
+	}
+
+	return buf.String()
+}
+
+func compileCheckRunE(mgr *manager.Manager) runEFunc {
+	return func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		format := flagString(cmd, "format")
+
+		versions, err := matrixVersions(cmd, mgr)
+		if err != nil {
+			return err
+		}
+
+		dirs, _ := cmd.Flags().GetStringArray("directory")
+
+		results := make([]*compileCheckVersion, len(versions))
+		runMatrix(len(versions), matrixJobs(cmd),
+			func(i int) {
+				res := &compileCheckVersion{Version: versions[i]}
+				results[i] = res
+
+				err := compileCheckVersionRun(ctx, mgr, res, dirs, args)
+				if err != nil {
+					res.Error = err.Error()
+				}
+			},
+			func(int) {},
+		)
+
+		output := newCompileCheckOutput(results)
+		err = render.Pretty(cmd.OutOrStdout(), format, output)
+		if err != nil {
+			return err
+		}
+
+		failed := 0
+		for _, ver := range results {
+			if ver.Error != "" {
+				failed++
+			}
+		}
+		switch {
+		case failed > 0:
+			return fmt.Errorf(
+				"Byte-compilation failed for %d of %d versions.",
+				failed, len(results),
+			)
+		case len(output.Warnings) > 0:
+			return fmt.Errorf("Found %d warnings or errors.", len(output.Warnings))
+		}
+
+		return nil
+	}
+}
+
+func compileCheckVersionRun(
+	ctx context.Context,
+	mgr *manager.Manager,
+	res *compileCheckVersion,
+	dirs []string,
+	files []string,
+) error {
+	tmpDir, err := os.MkdirTemp("", "evm-compile-check-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	emacsArgs := []string{"-Q", "--batch"}
+	for _, dir := range dirs {
+		emacsArgs = append(emacsArgs, "-L", dir)
+	}
+	// Compiled files are written to tmpDir under their source's full path, so
+	// files with the same name in different directories do not collide.
+	emacsArgs = append(emacsArgs,
+		"--eval", fmt.Sprintf(
+			"(setq byte-compile-dest-file-function "+
+				"(lambda (file) (let ((dest (expand-file-name "+
+				"(concat (file-relative-name (expand-file-name file) \"/\") "+
+				"\"c\") %s))) "+
+				"(make-directory (file-name-directory dest) t) dest)))",
+			elispString(tmpDir),
+		),
+		"-f", "batch-byte-compile",
+	)
+	emacsArgs = append(emacsArgs, files...)
+
+	c, err := mgr.Command(ctx, res.Version, "emacs", emacsArgs)
+	if err != nil {
+		return err
+	}
+
+	output, code, _, err := runCaptured(c)
+	res.Output = string(output)
+	res.ExitCode = code
+	if err != nil {
+		return err
+	}
+
+	res.Warnings = parseBytecompOutput(output)
+
+	hasError := false
+	for _, w := range res.Warnings {
+		if w.Severity == "error" {
+			hasError = true
+		}
+	}
+	if code != 0 && !hasError {
+		return fmt.Errorf("Emacs exited with status %d", code)
+	}
+
+	return nil
+}
@@ -10,7 +10,7 @@ func NewConfig(mgr *manager.Manager) (*cobra.Command, error) {
 	cmd := &cobra.Command{
 		Use:       "config",
 		Short:     "Show evm environment/setup details",
-		Aliases:   []string{"env", "info"},
+		Aliases:   []string{"info"},
 		ValidArgs: []string{},
 		RunE:      configRunE(mgr),
 	}
@@ -0,0 +1,167 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jimeh/evm/manager"
+	"github.com/jimeh/go-render"
+	"github.com/spf13/cobra"
+)
+
+func NewCurrent(mgr *manager.Manager) (*cobra.Command, error) {
+	cmd := &cobra.Command{
+		Use:               "current",
+		Short:             "Show the current Emacs version and how it was set",
+		Aliases:           []string{"version"},
+		Args:              cobra.ExactArgs(0),
+		SilenceUsage:      true,
+		ValidArgsFunction: noValidArgs,
+		RunE:              currentRunE(mgr),
+	}
+
+	cmd.Flags().StringP(
+		"format", "f", "text", "output format, \"text\", \"yaml\", or \"json\"",
+	)
+	cmd.Flags().BoolP(
+		"explain", "e", false, "show every source checked, in order",
+	)
+	cmd.Flags().BoolP(
+		"bare", "b", false, "only print the version",
+	)
+
+	return cmd, nil
+}
+
+func currentRunE(mgr *manager.Manager) runEFunc {
+	return func(cmd *cobra.Command, _ []string) error {
+		format := flagString(cmd, "format")
+		explain, _ := cmd.Flags().GetBool("explain")
+
+		if len(mgr.CurrentVersions()) == 0 && !explain {
+			return newExecNoCurrentVersionError()
+		}
+
+		if bare, _ := cmd.Flags().GetBool("bare"); bare {
+			_, err := fmt.Fprintln(
+				cmd.OutOrStdout(),
+				strings.Join(mgr.CurrentVersions(), " "),
+			)
+
+			return err
+		}
+
+		output := &currentOutput{
+			Version:  mgr.CurrentVersion(),
+			Versions: mgr.CurrentVersions(),
+			SetBy:    mgr.CurrentSetBy(),
+		}
+
+		if explain {
+			output.Sources = mgr.CurrentSources()
+			for _, v := range output.Versions {
+				output.Fallbacks = append(
+					output.Fallbacks,
+					&currentOutputVersion{
+						Version:   v,
+						Installed: versionInstalled(cmd, mgr, v),
+					},
+				)
+			}
+		}
+
+		return render.Pretty(cmd.OutOrStdout(), format, output)
+	}
+}
+
+func versionInstalled(
+	cmd *cobra.Command,
+	mgr *manager.Manager,
+	version string,
+) bool {
+	if version == manager.SystemVersion {
+		return true
+	}
+
+	_, err := mgr.Get(cmd.Context(), version)
+
+	return err == nil
+}
+
+type currentOutput struct {
+	Version   string                   `yaml:"version" json:"version"`
+	Versions  []string                 `yaml:"versions" json:"versions"`
+	SetBy     string                   `yaml:"set_by,omitempty" json:"set_by,omitempty"`
+	Sources   []*manager.CurrentSource `yaml:"sources,omitempty" json:"sources,omitempty"`
+	Fallbacks []*currentOutputVersion  `yaml:"fallbacks,omitempty" json:"fallbacks,omitempty"`
+}
+
+type currentOutputVersion struct {
+	Version   string `yaml:"version" json:"version"`
+	Installed bool   `yaml:"installed" json:"installed"`
+}
+
+func (co *currentOutput) String() string {
+	buf := &strings.Builder{}
+
+	if len(co.Versions) == 0 {
+		buf.WriteString("No current Emacs version is set.\n")
+	} else {
+		buf.WriteString(strings.Join(co.Versions, " "))
+		if co.SetBy != "" {
+			buf.WriteString(" (set by " + co.SetBy + ")")
+		}
+		buf.WriteByte('\n')
+	}
+
+	if co.Sources == nil {
+		return buf.String()
+	}
+
+	buf.WriteString("\nSources, in order of precedence:\n")
+	selected := false
+	for _, src := range co.Sources {
+		if src.Selected {
+			buf.WriteString("* ")
+		} else {
+			buf.WriteString("  ")
+		}
+		buf.WriteString(src.Source + ": ")
+
+		switch {
+		case !src.Exists && src.Type == manager.EnvSource:
+			buf.WriteString("not set")
+		case !src.Exists:
+			buf.WriteString("not found")
+		case len(src.Versions) == 0:
+			buf.WriteString("no version")
+		default:
+			buf.WriteString(strings.Join(src.Versions, " "))
+		}
+
+		switch {
+		case src.Ignored != "":
+			buf.WriteString(" (ignored, " + src.Ignored + ")")
+		case src.Selected:
+			selected = true
+			buf.WriteString(" (selected)")
+		case selected && len(src.Versions) > 0:
+			buf.WriteString(" (overridden)")
+		}
+
+		buf.WriteByte('\n')
+	}
+
+	if len(co.Fallbacks) > 0 {
+		buf.WriteString("\nVersions, in order of fallback:\n")
+		for _, ver := range co.Fallbacks {
+			buf.WriteString("  " + ver.Version)
+			if !ver.Installed {
+				buf.WriteString(" (not installed)")
+			}
+			buf.WriteByte('\n')
+		}
+	}
+
+	return buf.String()
+}
@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/jimeh/evm/manager"
+	"github.com/spf13/cobra"
+)
+
+func NewDesktop(mgr *manager.Manager) (*cobra.Command, error) {
+	cmd := &cobra.Command{
+		Use:   "desktop",
+		Short: "Manage desktop entries for installed Emacs versions",
+	}
+
+	syncCmd := &cobra.Command{
+		Use: "sync",
+		Short: "Write desktop entries for all installed versions, and " +
+			"remove stale ones",
+		Args: cobra.NoArgs,
+		RunE: desktopSyncRunE(mgr),
+	}
+
+	cleanCmd := &cobra.Command{
+		Use:   "clean",
+		Short: "Remove all desktop entries written by evm",
+		Args:  cobra.NoArgs,
+		RunE:  desktopCleanRunE(mgr),
+	}
+
+	cmd.AddCommand(syncCmd, cleanCmd)
+
+	return cmd, nil
+}
+
+func desktopSyncRunE(mgr *manager.Manager) runEFunc {
+	return func(cmd *cobra.Command, _ []string) error {
+		written, removed, err := mgr.SyncDesktopEntries(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		for _, path := range written {
+			fmt.Fprintf(cmd.OutOrStdout(), "Wrote %s\n", path)
+		}
+		for _, path := range removed {
+			fmt.Fprintf(cmd.OutOrStdout(), "Removed %s\n", path)
+		}
+
+		return nil
+	}
+}
+
+func desktopCleanRunE(mgr *manager.Manager) runEFunc {
+	return func(cmd *cobra.Command, _ []string) error {
+		removed, err := mgr.CleanDesktopEntries(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		for _, path := range removed {
+			fmt.Fprintf(cmd.OutOrStdout(), "Removed %s\n", path)
+		}
+
+		return nil
+	}
+}
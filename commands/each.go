@@ -0,0 +1,145 @@
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jimeh/evm/manager"
+	"github.com/spf13/cobra"
+)
+
+func NewEach(mgr *manager.Manager) (*cobra.Command, error) {
+	cmd := &cobra.Command{
+		Use:   "each [flags] [--] <command> [<args>...]",
+		Short: "Run a command with each of multiple Emacs versions",
+		Long: `Run a command with the environment of each selected Emacs version, as
+"evm with" does. Output is captured per version, and a summary is printed
+once all versions have finished. Exits with a non-zero status if the command
+failed for any version.
+
+Example:
+
+  evm each --match ">=28" -j 4 -- make test`,
+		Args:              cobra.MinimumNArgs(1),
+		SilenceUsage:      true,
+		ValidArgsFunction: noValidArgs,
+		RunE:              eachRunE(mgr),
+	}
+
+	// Flags after the command belong to the command being run.
+	cmd.Flags().SetInterspersed(false)
+	addMatrixFlags(cmd, mgr)
+	addJobsFlag(cmd)
+
+	return cmd, nil
+}
+
+type eachResult struct {
+	Version  string
+	Output   []byte
+	ExitCode int
+	Duration time.Duration
+	Err      error
+}
+
+func (er *eachResult) Status() string {
+	switch {
+	case er.Err != nil:
+		return "error"
+	case er.ExitCode == 0:
+		return "ok"
+	case er.ExitCode < 0:
+		return "killed"
+	default:
+		return fmt.Sprintf("exit %d", er.ExitCode)
+	}
+}
+
+func eachRunE(mgr *manager.Manager) runEFunc {
+	return func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		out := cmd.OutOrStdout()
+
+		versions, err := matrixVersions(cmd, mgr)
+		if err != nil {
+			return err
+		}
+
+		results := make([]*eachResult, len(versions))
+		runMatrix(len(versions), matrixJobs(cmd),
+			func(i int) {
+				res := &eachResult{Version: versions[i]}
+				results[i] = res
+
+				c, err := mgr.Command(ctx, versions[i], args[0], args[1:])
+				if err != nil {
+					res.Err = err
+					return
+				}
+
+				res.Output, res.ExitCode, res.Duration, res.Err = runCaptured(c)
+			},
+			func(i int) {
+				res := results[i]
+				fmt.Fprintf(out, "==> Emacs %s\n", res.Version)
+				_, _ = out.Write(res.Output)
+				if !bytes.HasSuffix(res.Output, []byte("\n")) &&
+					len(res.Output) > 0 {
+					fmt.Fprintln(out)
+				}
+				if res.Err != nil {
+					fmt.Fprintf(out, "%s\n", res.Err)
+				}
+				fmt.Fprintln(out)
+			},
+		)
+
+		failed := 0
+		tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "VERSION\tSTATUS\tTIME")
+		for _, res := range results {
+			if res.Err != nil || res.ExitCode != 0 {
+				failed++
+			}
+			fmt.Fprintf(
+				tw, "%s\t%s\t%s\n",
+				res.Version, res.Status(), res.Duration.Round(time.Millisecond),
+			)
+		}
+		err = tw.Flush()
+		if err != nil {
+			return err
+		}
+
+		if failed > 0 {
+			return fmt.Errorf(
+				"Command failed for %d of %d versions.", failed, len(results),
+			)
+		}
+
+		return nil
+	}
+}
+
+// Runs c with stdout and stderr captured together, returning the output, exit
+// code and wall time. A non-nil error is only returned if c failed to start.
+func runCaptured(c *exec.Cmd) ([]byte, int, time.Duration, error) {
+	var buf bytes.Buffer
+	c.Stdout = &buf
+	c.Stderr = &buf
+
+	start := time.Now()
+	err := c.Run()
+	duration := time.Since(start)
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return buf.Bytes(), exitErr.ExitCode(), duration, nil
+	}
+
+	return buf.Bytes(), 0, duration, err
+}
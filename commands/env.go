@@ -0,0 +1,129 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jimeh/evm/manager"
+	"github.com/jimeh/go-render"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+func NewEnv(mgr *manager.Manager) (*cobra.Command, error) {
+	cmd := &cobra.Command{
+		Use:   "env [<version>]",
+		Short: "Print the environment used to run programs from a version",
+		Long: `Print the environment used to run programs from a version, defaulting
+to the current version.
+
+Set up a shell or CI job without going through shims with:
+
+  eval "$(evm env 29.4)"`,
+		Args:              cobra.MaximumNArgs(1),
+		SilenceUsage:      true,
+		ValidArgsFunction: envValidArgs(mgr),
+		RunE:              envRunE(mgr),
+	}
+
+	cmd.Flags().StringP(
+		"format", "f", shShell,
+		"output format, \"sh\", \"fish\", \"json\", or \"dotenv\"",
+	)
+
+	return cmd, nil
+}
+
+func envRunE(mgr *manager.Manager) runEFunc {
+	return func(cmd *cobra.Command, args []string) error {
+		format := flagString(cmd, "format")
+
+		// "env" used to be an alias of "config", which printed YAML by
+		// default.
+		if format == "yaml" {
+			log.Warn().Msg(
+				`"evm env --format yaml" is deprecated, use "evm config"`,
+			)
+
+			return configRunE(mgr)(cmd, nil)
+		}
+
+		version := mgr.CurrentVersion()
+		if len(args) > 0 {
+			version = args[0]
+		}
+		if version == "" {
+			return newExecNoCurrentVersionError()
+		}
+
+		env, err := mgr.VersionEnv(cmd.Context(), version)
+		if err != nil {
+			return err
+		}
+
+		var b strings.Builder
+		switch format {
+		case shShell, bashShell, zshShell, fishShell:
+			for _, e := range env {
+				if e.Name == "PATH" && format == fishShell {
+					b.WriteString(shellExportList(
+						format, e.Name, strings.Split(e.Value, ":"),
+					))
+				} else {
+					b.WriteString(shellExport(format, e.Name, e.Value))
+				}
+			}
+		case "dotenv":
+			for _, e := range env {
+				b.WriteString(e.Name + "=" + dotenvQuote(e.Value) + "\n")
+			}
+		case "json":
+			output := map[string]string{}
+			for _, e := range env {
+				output[e.Name] = e.Value
+			}
+
+			return render.Pretty(cmd.OutOrStdout(), format, output)
+		default:
+			return fmt.Errorf(
+				`Unsupported format "%s", must be one of "sh", "fish", `+
+					`"json", or "dotenv".`,
+				format,
+			)
+		}
+
+		_, err = fmt.Fprint(cmd.OutOrStdout(), b.String())
+
+		return err
+	}
+}
+
+func dotenvQuote(s string) string {
+	if !strings.ContainsAny(s, " \t\n\"'\\$#`=") {
+		return s
+	}
+
+	r := strings.NewReplacer(
+		`\`, `\\`,
+		`"`, `\"`,
+		"\n", `\n`,
+		"$", `\$`,
+		"`", "\\`",
+	)
+
+	return `"` + r.Replace(s) + `"`
+}
+
+func envValidArgs(mgr *manager.Manager) validArgsFunc {
+	return func(
+		cmd *cobra.Command,
+		args []string,
+		toComplete string,
+	) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		return useValidArgs(mgr)(cmd, args, toComplete)
+	}
+}
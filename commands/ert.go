@@ -0,0 +1,107 @@
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Test statuses shown in reports. ERT results matching the test's expected
+// result are reported as passing.
+const (
+	ertPass       = "pass"
+	ertFail       = "fail"
+	ertSkip       = "skip"
+	ertUnexpected = "unexpected"
+)
+
+var (
+	ertResultRegexp = regexp.MustCompile(
+		`^\s+(passed|failed|skipped|quit|aborted|` +
+			`PASSED|FAILED|SKIPPED|QUIT|ABORTED)\s+\d+/\d+\s+(\S+)` +
+			`(?:\s+\(([\d.]+) sec\))?(?:\s+at\s+(\S+))?\s*$`,
+	)
+	ertSectionRegexp = regexp.MustCompile(
+		`^Test (\S+) (condition|backtrace):$`,
+	)
+)
+
+type ertTest struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	Result    string  `json:"result"`
+	Duration  float64 `json:"duration,omitempty"`
+	Location  string  `json:"location,omitempty"`
+	Condition string  `json:"condition,omitempty"`
+}
+
+// Parses the output of ert-run-tests-batch into per-test results.
+func parseERTOutput(output []byte) []*ertTest {
+	var tests []*ertTest
+	conditions := map[string]string{}
+
+	var section, sectionTest string
+	var lines []string
+	endSection := func() {
+		if section == "condition" {
+			conditions[sectionTest] = strings.Join(lines, "\n")
+		}
+		section, sectionTest, lines = "", "", nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if m := ertResultRegexp.FindStringSubmatch(line); m != nil {
+			endSection()
+
+			t := &ertTest{
+				Name:     m[2],
+				Status:   ertStatus(m[1]),
+				Result:   m[1],
+				Location: m[4],
+			}
+			t.Duration, _ = strconv.ParseFloat(m[3], 64)
+			t.Condition = conditions[t.Name]
+			tests = append(tests, t)
+
+			continue
+		}
+
+		if m := ertSectionRegexp.FindStringSubmatch(line); m != nil {
+			endSection()
+			section, sectionTest = m[2], m[1]
+
+			continue
+		}
+
+		if section != "" {
+			if strings.HasPrefix(line, " ") {
+				lines = append(lines, strings.TrimPrefix(line, "    "))
+			} else {
+				endSection()
+			}
+		}
+	}
+
+	return tests
+}
+
+// ERT reports results in lower case when they match the test's expected
+// result, and in upper case otherwise.
+func ertStatus(result string) string {
+	switch result {
+	case "skipped", "SKIPPED":
+		return ertSkip
+	case "passed", "failed", "quit", "aborted":
+		return ertPass
+	case "PASSED":
+		return ertUnexpected
+	default:
+		return ertFail
+	}
+}
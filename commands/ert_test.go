@@ -0,0 +1,303 @@
+package commands
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const ertOutputEmacs27 = `Running 4 tests (2021-03-25 12:00:00+0000, selector ` + "`t'" + `)
+   passed  1/4  foo-test
+Test bar-test backtrace:
+  signal(ert-test-failed (((should (= 1 2)) :form (= 1 2) :value nil)))
+  ert-fail(((should (= 1 2)) :form (= 1 2) :value nil))
+  (if (unwind-protect (setq value-2 (apply fn-0 args-1)) (setq form-descr
+  (let (form-descr-4) (if (unwind-protect (setq value-2 (apply fn-0 args-
+  (let ((value-2 'ert-form-evaluation-aborted-3)) (let (form-descr-4) (if
+Test bar-test condition:
+    (ert-test-failed
+     ((should
+       (= 1 2))
+      :form
+      (= 1 2)
+      :value nil))
+   FAILED  2/4  bar-test
+  skipped  3/4  baz-test
+   passed  4/4  qux-test
+
+Ran 4 tests, 2 results as expected, 1 unexpected, 1 skipped (2021-03-25 12:00:00+0000, 0.210442 sec)
+
+1 unexpected results:
+   FAILED  bar-test
+
+`
+
+const ertOutputEmacs28 = `Running 4 tests (2022-09-12 12:00:00+0000, selector ` + "`t'" + `)
+   passed  1/4  foo-test (0.000052 sec)
+Test bar-test backtrace:
+  signal(ert-test-failed (((should (= 1 2)) :form (= 1 2) :value nil)))
+  ert-fail(((should (= 1 2)) :form (= 1 2) :value nil))
+Test bar-test condition:
+    (ert-test-failed
+     ((should
+       (= 1 2))
+      :form
+      (= 1 2)
+      :value nil))
+   FAILED  2/4  bar-test (0.000130 sec)
+  skipped  3/4  baz-test (0.000027 sec)
+   PASSED  4/4  qux-test (0.000031 sec)
+
+Ran 4 tests, 1 results as expected, 2 unexpected, 1 skipped (2022-09-12 12:00:00+0000, 0.120442 sec)
+
+2 unexpected results:
+   FAILED  bar-test
+   PASSED  qux-test
+
+1 skipped results:
+  SKIPPED  baz-test
+
+`
+
+const ertOutputEmacs29 = `Running 5 tests (2024-06-22 12:00:00+0000, selector ‘t’)
+   passed  1/5  foo-test (0.000052 sec)
+Test bar-test backtrace:
+  signal(ert-test-failed (((should (= 1 2)) :form (= 1 2) :value nil)))
+  ert-fail(((should (= 1 2)) :form (= 1 2) :value nil))
+  ert-run-tests-batch-and-exit(t)
+  command-line-1(("-l" "test.el" "--eval" "(ert-run-tests-batch-and-exit t)"))
+Test bar-test condition:
+    (ert-test-failed
+     ((should (= 1 2)) :form (= 1 2) :value nil))
+   FAILED  2/5  bar-test (0.000130 sec) at test/foo-test.el:12
+  skipped  3/5  baz-test (0.000027 sec)
+   failed  4/5  expected-failure-test (0.000040 sec)
+   passed  5/5  ns/with.odd-name (0.000010 sec)
+
+Ran 5 tests, 3 results as expected, 1 unexpected, 1 skipped (2024-06-22 12:00:00+0000, 0.094224 sec)
+
+1 unexpected results:
+   FAILED  bar-test  ((should (= 1 2)) :form (= 1 2) :value nil)
+
+1 skipped results:
+  SKIPPED  baz-test  ((skip-unless nil) :form nil :value nil)
+
+`
+
+const ertFailCondition = `(ert-test-failed
+ ((should
+   (= 1 2))
+  :form
+  (= 1 2)
+  :value nil))`
+
+func TestParseERTOutput(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []*ertTest
+	}{
+		{
+			name:   "empty",
+			output: "",
+			want:   nil,
+		},
+		{
+			name:   "load error",
+			output: "Cannot open load file: No such file or directory, foo\n",
+			want:   nil,
+		},
+		{
+			name:   "Emacs 27 without durations",
+			output: ertOutputEmacs27,
+			want: []*ertTest{
+				{Name: "foo-test", Status: ertPass, Result: "passed"},
+				{
+					Name:      "bar-test",
+					Status:    ertFail,
+					Result:    "FAILED",
+					Condition: ertFailCondition,
+				},
+				{Name: "baz-test", Status: ertSkip, Result: "skipped"},
+				{Name: "qux-test", Status: ertPass, Result: "passed"},
+			},
+		},
+		{
+			name:   "Emacs 28 with durations",
+			output: ertOutputEmacs28,
+			want: []*ertTest{
+				{
+					Name:     "foo-test",
+					Status:   ertPass,
+					Result:   "passed",
+					Duration: 0.000052,
+				},
+				{
+					Name:      "bar-test",
+					Status:    ertFail,
+					Result:    "FAILED",
+					Duration:  0.000130,
+					Condition: ertFailCondition,
+				},
+				{
+					Name:     "baz-test",
+					Status:   ertSkip,
+					Result:   "skipped",
+					Duration: 0.000027,
+				},
+				{
+					Name:     "qux-test",
+					Status:   ertUnexpected,
+					Result:   "PASSED",
+					Duration: 0.000031,
+				},
+			},
+		},
+		{
+			name:   "Emacs 29 with locations",
+			output: ertOutputEmacs29,
+			want: []*ertTest{
+				{
+					Name:     "foo-test",
+					Status:   ertPass,
+					Result:   "passed",
+					Duration: 0.000052,
+				},
+				{
+					Name:     "bar-test",
+					Status:   ertFail,
+					Result:   "FAILED",
+					Duration: 0.000130,
+					Location: "test/foo-test.el:12",
+					Condition: "(ert-test-failed\n" +
+						" ((should (= 1 2)) :form (= 1 2) :value nil))",
+				},
+				{
+					Name:     "baz-test",
+					Status:   ertSkip,
+					Result:   "skipped",
+					Duration: 0.000027,
+				},
+				{
+					Name:     "expected-failure-test",
+					Status:   ertPass,
+					Result:   "failed",
+					Duration: 0.000040,
+				},
+				{
+					Name:     "ns/with.odd-name",
+					Status:   ertPass,
+					Result:   "passed",
+					Duration: 0.000010,
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseERTOutput([]byte(tt.output))
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("parseERTOutput() returned %d tests, want %d",
+					len(got), len(tt.want))
+			}
+			for i := range got {
+				if !reflect.DeepEqual(got[i], tt.want[i]) {
+					t.Errorf("parseERTOutput()[%d] = %#v, want %#v",
+						i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestERTStatus(t *testing.T) {
+	tests := []struct {
+		result string
+		want   string
+	}{
+		{result: "passed", want: ertPass},
+		{result: "failed", want: ertPass},
+		{result: "quit", want: ertPass},
+		{result: "aborted", want: ertPass},
+		{result: "skipped", want: ertSkip},
+		{result: "SKIPPED", want: ertSkip},
+		{result: "PASSED", want: ertUnexpected},
+		{result: "FAILED", want: ertFail},
+		{result: "QUIT", want: ertFail},
+		{result: "ABORTED", want: ertFail},
+	}
+	for _, tt := range tests {
+		t.Run(tt.result, func(t *testing.T) {
+			if got := ertStatus(tt.result); got != tt.want {
+				t.Errorf("ertStatus(%q) = %q, want %q",
+					tt.result, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteJUnitReport(t *testing.T) {
+	results := []*testResult{
+		{
+			Version:  "28.2",
+			Duration: 1.5,
+			Error:    "Emacs exited with status 255",
+			Output:   "Cannot open load file\n",
+		},
+		{
+			Version:  "29.4",
+			Duration: 0.5,
+			Tests:    parseERTOutput([]byte(ertOutputEmacs28)),
+		},
+	}
+
+	path := filepath.Join(t.TempDir(), "report.xml")
+	err := writeJUnitReport(path, results)
+	if err != nil {
+		t.Fatalf("writeJUnitReport() error = %v", err)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var report junitTestSuites
+	err = xml.Unmarshal(b, &report)
+	if err != nil {
+		t.Fatalf("report is not valid XML: %v", err)
+	}
+
+	type counts struct {
+		Name                             string
+		Tests, Failures, Errors, Skipped int
+		Cases                            int
+	}
+	var got []counts
+	for _, s := range report.Suites {
+		got = append(got, counts{
+			s.Name, s.Tests, s.Failures, s.Errors, s.Skipped, len(s.Cases),
+		})
+	}
+	want := []counts{
+		{Name: "Emacs 28.2", Tests: 1, Errors: 1, Cases: 1},
+		{
+			Name: "Emacs 29.4", Tests: 4, Failures: 2, Skipped: 1, Cases: 4,
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("suite counts = %+v, want %+v", got, want)
+	}
+
+	bar := report.Suites[1].Cases[1]
+	if bar.Name != "bar-test" || bar.Failure == nil ||
+		bar.Failure.Text != ertFailCondition {
+		t.Errorf("bar-test case = %+v, want failure with condition", bar)
+	}
+	if report.Suites[0].Cases[0].Error == nil {
+		t.Error("version error was not reported as a JUnit error")
+	}
+}
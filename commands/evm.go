@@ -10,11 +10,12 @@ func NewEvm(mgr *manager.Manager) (*cobra.Command, error) {
 		Use: "evm",
 		Short: "A simple and opinionated Emacs Version Manager " +
 			"and build tool",
-		PersistentPreRunE: PersistentPreRunE,
+		PersistentPreRunE: persistentPreRunE(mgr),
 	}
 
-	cmd.PersistentFlags().StringP(
-		"log-level", "l", "info",
+	// The "-l" shorthand is left for loading elisp files, as with Emacs.
+	cmd.PersistentFlags().String(
+		"log-level", "info",
 		"one of: trace, debug, info, warn, error, fatal, panic",
 	)
 
@@ -28,6 +29,11 @@ func NewEvm(mgr *manager.Manager) (*cobra.Command, error) {
 		return nil, err
 	}
 
+	currentCmd, err := NewCurrent(mgr)
+	if err != nil {
+		return nil, err
+	}
+
 	useCmd, err := NewUse(mgr)
 	if err != nil {
 		return nil, err
@@ -43,12 +49,97 @@ func NewEvm(mgr *manager.Manager) (*cobra.Command, error) {
 		return nil, err
 	}
 
+	shimsCmd, err := NewShims(mgr)
+	if err != nil {
+		return nil, err
+	}
+
+	whichCmd, err := NewWhich(mgr)
+	if err != nil {
+		return nil, err
+	}
+
+	whenceCmd, err := NewWhence(mgr)
+	if err != nil {
+		return nil, err
+	}
+
+	trustCmd, err := NewTrust(mgr)
+	if err != nil {
+		return nil, err
+	}
+
+	untrustCmd, err := NewUntrust(mgr)
+	if err != nil {
+		return nil, err
+	}
+
+	desktopCmd, err := NewDesktop(mgr)
+	if err != nil {
+		return nil, err
+	}
+
+	initCmd, err := NewInit(mgr)
+	if err != nil {
+		return nil, err
+	}
+
+	shellCmd, err := NewShell(mgr)
+	if err != nil {
+		return nil, err
+	}
+
+	envCmd, err := NewEnv(mgr)
+	if err != nil {
+		return nil, err
+	}
+
+	withCmd, err := NewWith(mgr)
+	if err != nil {
+		return nil, err
+	}
+
+	eachCmd, err := NewEach(mgr)
+	if err != nil {
+		return nil, err
+	}
+
+	testCmd, err := NewTest(mgr)
+	if err != nil {
+		return nil, err
+	}
+
+	compileCheckCmd, err := NewCompileCheck(mgr)
+	if err != nil {
+		return nil, err
+	}
+
+	benchCmd, err := NewBench(mgr)
+	if err != nil {
+		return nil, err
+	}
+
 	cmd.AddCommand(
 		configCmd,
 		listCmd,
+		currentCmd,
 		useCmd,
 		rehashCmd,
 		execCmd,
+		shimsCmd,
+		whichCmd,
+		whenceCmd,
+		trustCmd,
+		untrustCmd,
+		desktopCmd,
+		initCmd,
+		shellCmd,
+		envCmd,
+		withCmd,
+		eachCmd,
+		testCmd,
+		compileCheckCmd,
+		benchCmd,
 	)
 
 	return cmd, nil
@@ -2,9 +2,9 @@ package commands
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"html/template"
-	"path/filepath"
 	"strings"
 
 	"github.com/jimeh/evm/manager"
@@ -30,36 +30,53 @@ func NewExec(mgr *manager.Manager) (*cobra.Command, error) {
 
 func execRunE(mgr *manager.Manager) runEFunc {
 	return func(cmd *cobra.Command, args []string) error {
-		ctx := cmd.Context()
-
 		program := args[0]
 		args = args[1:]
 
-		err := mgr.Exec(ctx, program, args)
-		if err != nil {
-			if errors.Is(err, manager.ErrBinNotFound) {
-				var versions []*manager.Version
-				versions, err = mgr.FindBin(ctx, program)
-				if err != nil {
-					return err
-				}
-
-				return newExecOtherVersionsError(&execOtherVersionsData{
-					Name:        program,
-					Current:     mgr.CurrentVersion(),
-					AvailableIn: versions,
-				})
-			}
+		return ExecShim(cmd.Context(), mgr, program, args)
+	}
+}
 
-			if errors.Is(err, manager.ErrNoCurrentVersion) {
-				return newExecNoCurrentVersionError()
-			}
+// ExecShim executes program for a shim, returning a user friendly error if
+// it cannot be executed.
+func ExecShim(
+	ctx context.Context,
+	mgr *manager.Manager,
+	program string,
+	args []string,
+) error {
+	err := mgr.Exec(ctx, program, args)
+	if err != nil {
+		return execError(ctx, mgr, program, err)
+	}
+
+	return nil
+}
 
+func execError(
+	ctx context.Context,
+	mgr *manager.Manager,
+	program string,
+	err error,
+) error {
+	if errors.Is(err, manager.ErrBinNotFound) {
+		versions, err := mgr.FindBin(ctx, program)
+		if err != nil {
 			return err
 		}
 
-		return nil
+		return newExecOtherVersionsError(&execOtherVersionsData{
+			Name:        program,
+			Current:     mgr.CurrentFor(program).Versions,
+			AvailableIn: versions,
+		})
 	}
+
+	if errors.Is(err, manager.ErrNoCurrentVersion) {
+		return newExecNoCurrentVersionError()
+	}
+
+	return err
 }
 
 func execValidArgs(mgr *manager.Manager) validArgsFunc {
@@ -74,15 +91,18 @@ func execValidArgs(mgr *manager.Manager) validArgsFunc {
 			return nil, cobra.ShellCompDirectiveNoFileComp
 		}
 
-		version, err := mgr.Get(cmd.Context(), mgr.CurrentVersion())
+		shims, err := mgr.Shims(cmd.Context())
 		if err != nil {
 			return nil, cobra.ShellCompDirectiveNoFileComp
 		}
 
-		for _, bin := range version.Binaries {
-			base := filepath.Base(bin)
-			if toComplete == "" || strings.HasPrefix(base, toComplete) {
-				r = append(r, base)
+		for _, shim := range shims {
+			if shim.Orphaned {
+				continue
+			}
+
+			if toComplete == "" || strings.HasPrefix(shim.Name, toComplete) {
+				r = append(r, shim.Name)
 			}
 		}
 
@@ -100,10 +120,14 @@ Change version with: evm use <version>`,
 
 type execOtherVersionsData struct {
 	Name        string
-	Current     string
+	Current     []string
 	AvailableIn []*manager.Version
 }
 
+func (d *execOtherVersionsData) CurrentList() string {
+	return strings.Join(d.Current, ", ")
+}
+
 func newExecOtherVersionsError(data *execOtherVersionsData) error {
 	var buf bytes.Buffer
 	err := execOtherVersionsTemplate.Execute(&buf, data)
@@ -116,7 +140,7 @@ func newExecOtherVersionsError(data *execOtherVersionsData) error {
 
 var execOtherVersionsTemplate = template.Must(template.New("other").Parse(
 	`{{ if gt (len .AvailableIn) 0 -}}
-Executable "{{.Name}}" is not available in the current Emacs version ({{.Current}}).
+Executable "{{.Name}}" is not available in the current Emacs {{ if gt (len .Current) 1 }}versions{{ else }}version{{ end }} ({{.CurrentList}}).
 
 "{{.Name}}" is available in the following Emacs versions:
 {{- range .AvailableIn }}
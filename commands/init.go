@@ -0,0 +1,221 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/jimeh/evm/manager"
+	"github.com/spf13/cobra"
+)
+
+func NewInit(mgr *manager.Manager) (*cobra.Command, error) {
+	cmd := &cobra.Command{
+		Use:   "init <shell>",
+		Short: "Print shell code to set up evm in your shell",
+		Long: `Print shell code which puts evm's shims on PATH, loads shell completions,
+and defines an evm shell function required by "evm shell".
+
+With --cd, the current version is resolved again whenever the working directory
+changes, and exported as EVM_RESOLVED_VERSION for use in shell prompts, for
+example:
+
+  PS1='[emacs ${EVM_RESOLVED_VERSION:-none}] \$ '
+
+Shims resolve the version on every call either way, so --cd is only needed to
+show the version in a prompt.
+
+Bash:
+
+  echo 'eval "$(evm init bash)"' >> ~/.bashrc
+
+Zsh:
+
+  echo 'eval "$(evm init zsh)"' >> ~/.zshrc
+
+Fish:
+
+  echo 'evm init fish | source' >> ~/.config/fish/config.fish`,
+		Args:         cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		ValidArgs:    initShells,
+		SilenceUsage: true,
+		RunE:         initRunE(mgr),
+	}
+
+	cmd.Flags().Bool(
+		"no-completion", false, "do not load shell completions",
+	)
+	cmd.Flags().Bool(
+		"cd", false,
+		"re-resolve the current version when changing directory, and "+
+			"export it as "+shellResolvedEnv+" for use in prompts",
+	)
+
+	return cmd, nil
+}
+
+func initRunE(mgr *manager.Manager) runEFunc {
+	return func(cmd *cobra.Command, args []string) error {
+		shell := args[0]
+		noCompletion, _ := cmd.Flags().GetBool("no-completion")
+		cdHook, _ := cmd.Flags().GetBool("cd")
+
+		var b strings.Builder
+		b.WriteString(shellExport(shell, "EVM_SHELL", shell))
+
+		conf := mgr.Config
+		if conf.Activation.Link() {
+			b.WriteString(initPrependPath(
+				shell, "PATH", filepath.Join(conf.Paths.Active, "bin"),
+			))
+		}
+		if conf.Activation.Shims() {
+			b.WriteString(initPrependPath(shell, "PATH", conf.Paths.Shims))
+		}
+		if conf.Share {
+			b.WriteString(initPrependPath(
+				shell, "MANPATH", filepath.Join(conf.Paths.Share, "man"),
+			))
+			b.WriteString(initPrependPath(
+				shell, "INFOPATH", filepath.Join(conf.Paths.Share, "info"),
+			))
+		}
+
+		if shell == fishShell {
+			b.WriteString(initFishFunctions(!noCompletion, cdHook))
+		} else {
+			b.WriteString(initShFunctions(shell, !noCompletion, cdHook))
+		}
+
+		_, err := fmt.Fprint(cmd.OutOrStdout(), b.String())
+
+		return err
+	}
+}
+
+// Returns shell code which prepends dir to the given path list variable,
+// unless it is already present. An empty MANPATH or INFOPATH element is kept
+// so the system defaults are still searched.
+func initPrependPath(shell string, name string, dir string) string {
+	q := shellQuote(shell, dir)
+
+	if shell == fishShell {
+		var b strings.Builder
+		if name != "PATH" {
+			fmt.Fprintf(&b, "set -q %s; or set -gx %s ''\n", name, name)
+		}
+		fmt.Fprintf(
+			&b, "contains -- %s $%s; or set -gx %s %s $%s\n",
+			q, name, name, q, name,
+		)
+
+		return b.String()
+	}
+
+	value := fmt.Sprintf(`%s:"${%s}"`, q, name)
+	if name == "PATH" {
+		value = fmt.Sprintf(`%s"${%s:+:${%s}}"`, q, name, name)
+	}
+
+	return fmt.Sprintf(
+		"case \":${%s}:\" in\n  *:%s:*) ;;\n  *) export %s=%s ;;\nesac\n",
+		name, q, name, value,
+	)
+}
+
+func initShFunctions(shell string, completion bool, cdHook bool) string {
+	var b strings.Builder
+
+	refresh := ""
+	if cdHook {
+		refresh = "\n    __evm_refresh"
+	}
+
+	fmt.Fprintf(&b, `evm() {
+  case "$1" in
+  shell)
+    shift
+    local script
+    script="$(command evm shell "$@")" || return
+    eval "${script}"%s
+    ;;
+  *)
+    command evm "$@"
+    ;;
+  esac
+}
+`, refresh)
+
+	if cdHook {
+		b.WriteString(`__evm_refresh() {
+  local script
+  script="$(command evm shell --refresh 2>/dev/null)" && eval "${script}"
+}
+`)
+
+		if shell == zshShell {
+			b.WriteString(`autoload -Uz add-zsh-hook
+add-zsh-hook chpwd __evm_refresh
+`)
+		} else {
+			b.WriteString(`__evm_hook() {
+  if [ "${__evm_pwd-}" != "${PWD}" ]; then
+    __evm_pwd="${PWD}"
+    __evm_refresh
+  fi
+}
+case ";${PROMPT_COMMAND-};" in
+  *";__evm_hook;"*) ;;
+  *) PROMPT_COMMAND="__evm_hook${PROMPT_COMMAND:+;${PROMPT_COMMAND}}" ;;
+esac
+`)
+		}
+		b.WriteString("__evm_refresh\n")
+	}
+
+	if completion {
+		if shell == zshShell {
+			b.WriteString(`if (( $+functions[compdef] )); then
+  eval "$(command evm completion zsh)"
+fi
+`)
+		} else {
+			b.WriteString("eval \"$(command evm completion bash)\"\n")
+		}
+	}
+
+	return b.String()
+}
+
+func initFishFunctions(completion bool, cdHook bool) string {
+	var b strings.Builder
+
+	refresh := ""
+	if cdHook {
+		refresh = "\n    __evm_refresh"
+	}
+
+	fmt.Fprintf(&b, `function evm
+  if test "$argv[1]" = shell
+    set -l script (command evm shell $argv[2..-1]); or return
+    string join \n -- $script | source%s
+  else
+    command evm $argv
+  end
+end
+`, refresh)
+
+	if cdHook {
+		b.WriteString(`function __evm_refresh --on-variable PWD
+  command evm shell --refresh 2>/dev/null | source
+end
+__evm_refresh
+`)
+	}
+
+	if completion {
+		b.WriteString("command evm completion fish | source\n")
+	}
+
+	return b.String()
+}
@@ -0,0 +1,100 @@
+package commands
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strings"
+)
+
+type junitTestSuites struct {
+	XMLName xml.Name          `xml:"testsuites"`
+	Suites  []*junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Cases    []*junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+func writeJUnitReport(path string, results []*testResult) error {
+	report := &junitTestSuites{}
+
+	for _, res := range results {
+		className := "emacs-" + res.Version
+		suite := &junitTestSuite{
+			Name: "Emacs " + res.Version,
+			Time: fmt.Sprintf("%.3f", res.Duration),
+		}
+
+		for _, t := range res.Tests {
+			tc := &junitTestCase{
+				Name:      t.Name,
+				ClassName: className,
+				Time:      fmt.Sprintf("%.6f", t.Duration),
+			}
+
+			switch t.Status {
+			case ertFail:
+				suite.Failures++
+				tc.Failure = &junitMessage{
+					Message: t.Result, Text: t.Condition,
+				}
+			case ertUnexpected:
+				suite.Failures++
+				tc.Failure = &junitMessage{Message: "unexpected pass"}
+			case ertSkip:
+				suite.Skipped++
+				tc.Skipped = &junitMessage{Text: t.Condition}
+			}
+
+			suite.Cases = append(suite.Cases, tc)
+		}
+
+		if res.Error != "" {
+			suite.Errors++
+			suite.Cases = append(suite.Cases, &junitTestCase{
+				Name:      "emacs",
+				ClassName: className,
+				Time:      suite.Time,
+				Error: &junitMessage{
+					Message: res.Error, Text: res.Output,
+				},
+			})
+		}
+
+		suite.Tests = len(suite.Cases)
+		report.Suites = append(report.Suites, suite)
+	}
+
+	b, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	var buf strings.Builder
+	buf.WriteString(xml.Header)
+	buf.Write(b)
+	buf.WriteString("\n")
+
+	return os.WriteFile(path, []byte(buf.String()), 0o644)
+}
@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"sort"
 	"strings"
 
 	"github.com/jimeh/evm/manager"
@@ -37,43 +38,119 @@ func listRunE(mgr *manager.Manager) runEFunc {
 
 		output := &listOutput{
 			Current: listOutputCurrent{
-				Version: mgr.CurrentVersion(),
-				SetBy:   mgr.CurrentSetBy(),
+				Version:  mgr.CurrentVersion(),
+				Versions: mgr.CurrentVersions(),
+				SetBy:    mgr.CurrentSetBy(),
 			},
 			Versions: versions,
 		}
 
+		output.Programs, err = mgr.PinnedPrograms(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		current := stringsContains(
+			mgr.CurrentVersions(), manager.SystemVersion,
+		)
+		path, err := mgr.FindSystemBin(systemProgram)
+		if err == nil || current {
+			output.System = &listOutputSystem{
+				Current: current,
+				Path:    path,
+			}
+		}
+
 		return render.Pretty(cmd.OutOrStdout(), format, output)
 	}
 }
 
+// Program used to show what the "system" pseudo-version resolves to.
+const systemProgram = "emacs"
+
 type listOutput struct {
-	Current  listOutputCurrent  `yaml:"current" json:"current"`
-	Versions []*manager.Version `yaml:"versions" json:"versions"`
+	Current  listOutputCurrent                 `yaml:"current" json:"current"`
+	System   *listOutputSystem                 `yaml:"system,omitempty" json:"system,omitempty"`
+	Versions []*manager.Version                `yaml:"versions" json:"versions"`
+	Programs map[string]*manager.CurrentConfig `yaml:"programs,omitempty" json:"programs,omitempty"`
+}
+
+type listOutputSystem struct {
+	Current bool   `yaml:"current" json:"current"`
+	Path    string `yaml:"path" json:"path"`
 }
 
 type listOutputCurrent struct {
-	Version string `yaml:"version" json:"version"`
-	SetBy   string `yaml:"set_by,omitempty" json:"set_by,omitempty"`
+	Version  string   `yaml:"version" json:"version"`
+	Versions []string `yaml:"versions,omitempty" json:"versions,omitempty"`
+	SetBy    string   `yaml:"set_by,omitempty" json:"set_by,omitempty"`
+}
+
+func (loc *listOutputCurrent) annotation(version string) string {
+	switch {
+	case version == loc.Version && loc.SetBy != "":
+		return " (set by " + loc.SetBy + ")"
+	case version != loc.Version && stringsContains(loc.Versions, version):
+		return " (fallback)"
+	}
+
+	return ""
 }
 
 func (lo *listOutput) String() string {
 	buf := &strings.Builder{}
 
+	if lo.System != nil {
+		if lo.System.Current {
+			buf.WriteString("* ")
+		} else {
+			buf.WriteString("  ")
+		}
+
+		buf.WriteString(manager.SystemVersion)
+		if lo.System.Path != "" {
+			buf.WriteString(" (" + lo.System.Path + ")")
+		} else {
+			buf.WriteString(" (" + systemProgram + " not found)")
+		}
+		if lo.System.Current {
+			buf.WriteString(lo.Current.annotation(manager.SystemVersion))
+		}
+
+		buf.WriteByte('\n')
+	}
+
 	for _, ver := range lo.Versions {
-		if lo.Current.Version == ver.Version {
+		if ver.Current {
 			buf.WriteString("* ")
 		} else {
 			buf.WriteString("  ")
 		}
 
 		buf.WriteString(ver.Version)
-		if lo.Current.Version == ver.Version && lo.Current.SetBy != "" {
-			buf.WriteString(" (set by " + lo.Current.SetBy + ")")
+		if ver.Current {
+			buf.WriteString(lo.Current.annotation(ver.Version))
 		}
 
 		buf.WriteByte('\n')
 	}
 
+	if len(lo.Programs) > 0 {
+		names := make([]string, 0, len(lo.Programs))
+		for name := range lo.Programs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		buf.WriteString("\nPinned programs:\n")
+		for _, name := range names {
+			cc := lo.Programs[name]
+			buf.WriteString(
+				"  " + name + ": " + strings.Join(cc.Versions, " ") +
+					" (set by " + cc.SetBy + ")\n",
+			)
+		}
+	}
+
 	return buf.String()
 }
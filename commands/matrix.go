@@ -0,0 +1,169 @@
+package commands
+
+import (
+	"errors"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/jimeh/evm/manager"
+	"github.com/spf13/cobra"
+)
+
+// Flags shared by commands which run something across multiple versions.
+func addMatrixFlags(cmd *cobra.Command, mgr *manager.Manager) {
+	cmd.Flags().StringSlice(
+		"versions", nil, "comma separated list of versions to use",
+	)
+	cmd.Flags().BoolP(
+		"all", "a", false, "use all installed versions (default)",
+	)
+	cmd.Flags().StringP(
+		"match", "m", "",
+		"use installed versions matching constraints, e.g. \">=28, <30\"",
+	)
+
+	_ = cmd.RegisterFlagCompletionFunc(
+		"versions", matrixVersionsValidArgs(mgr),
+	)
+}
+
+// Flag for commands which can run versions in parallel.
+func addJobsFlag(cmd *cobra.Command) {
+	cmd.Flags().IntP(
+		"jobs", "j", 1,
+		"number of versions to run in parallel, 0 for one per CPU",
+	)
+}
+
+// Returns the versions selected with the --versions, --all, or --match flags,
+// defaulting to all installed versions.
+func matrixVersions(
+	cmd *cobra.Command,
+	mgr *manager.Manager,
+) ([]string, error) {
+	ctx := cmd.Context()
+	versions, _ := cmd.Flags().GetStringSlice("versions")
+	all, _ := cmd.Flags().GetBool("all")
+	match := flagString(cmd, "match")
+
+	given := 0
+	for _, ok := range []bool{len(versions) > 0, all, match != ""} {
+		if ok {
+			given++
+		}
+	}
+	if given > 1 {
+		return nil, errors.New(
+			"Only one of --versions, --all, or --match can be given.",
+		)
+	}
+
+	if len(versions) > 0 {
+		for _, version := range versions {
+			if version == manager.SystemVersion {
+				continue
+			}
+
+			_, err := mgr.Get(ctx, version)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return versions, nil
+	}
+
+	var vers []*manager.Version
+	var err error
+	if match != "" {
+		vers, err = mgr.MatchVersions(ctx, match)
+	} else {
+		vers, err = mgr.List(ctx)
+		manager.SortVersions(vers)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(vers) == 0 {
+		return nil, errors.New("No installed Emacs versions selected.")
+	}
+
+	r := make([]string, 0, len(vers))
+	for _, ver := range vers {
+		r = append(r, ver.Version)
+	}
+
+	return r, nil
+}
+
+func matrixJobs(cmd *cobra.Command) int {
+	jobs, _ := cmd.Flags().GetInt("jobs")
+	if jobs <= 0 {
+		jobs = runtime.NumCPU()
+	}
+
+	return jobs
+}
+
+// Calls run for each index from 0 to n-1, with up to jobs calls running
+// concurrently. done is called for each index in order, as soon as run has
+// returned for it and all indexes before it.
+func runMatrix(n int, jobs int, run func(i int), done func(i int)) {
+	finished := make([]chan struct{}, n)
+	for i := range finished {
+		finished[i] = make(chan struct{})
+	}
+
+	sem := make(chan struct{}, jobs)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			run(i)
+			close(finished[i])
+		}(i)
+	}
+
+	for i := 0; i < n; i++ {
+		<-finished[i]
+		done(i)
+	}
+
+	wg.Wait()
+}
+
+func matrixVersionsValidArgs(mgr *manager.Manager) validArgsFunc {
+	return func(
+		cmd *cobra.Command,
+		_ []string,
+		toComplete string,
+	) ([]string, cobra.ShellCompDirective) {
+		versions, err := mgr.List(cmd.Context())
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		prefix := ""
+		if i := strings.LastIndex(toComplete, ","); i >= 0 {
+			prefix = toComplete[:i+1]
+		}
+		given := strings.Split(prefix, ",")
+
+		var r []string
+		for _, ver := range versions {
+			v := prefix + ver.Version
+			if !stringsContains(given, ver.Version) &&
+				strings.HasPrefix(v, toComplete) {
+				r = append(r, v)
+			}
+		}
+
+		return r, cobra.ShellCompDirectiveNoFileComp
+	}
+}
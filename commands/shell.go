@@ -0,0 +1,108 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jimeh/evm/manager"
+	"github.com/spf13/cobra"
+)
+
+const shellResolvedEnv = "EVM_RESOLVED_VERSION"
+
+func NewShell(mgr *manager.Manager) (*cobra.Command, error) {
+	cmd := &cobra.Command{
+		Use:   "shell [<version> [<fallback-version>...]]",
+		Short: "Set the Emacs version for the current shell session",
+		Long: `Set the Emacs version for the current shell session by setting the
+EVM_VERSION environment variable.
+
+Requires shell integration to be set up with "evm init".`,
+		SilenceUsage:      true,
+		ValidArgsFunction: useValidArgs(mgr),
+		RunE:              shellRunE(mgr),
+	}
+
+	// Output from the shell command is evaluated by the shell function, so
+	// help must go to stderr.
+	helpFunc := cmd.HelpFunc()
+	cmd.SetHelpFunc(func(c *cobra.Command, args []string) {
+		c.SetOut(c.ErrOrStderr())
+		helpFunc(c, args)
+	})
+
+	cmd.Flags().BoolP(
+		"unset", "u", false, "unset the shell session's Emacs version",
+	)
+	cmd.Flags().Bool(
+		"refresh", false, "export the currently resolved version",
+	)
+	_ = cmd.Flags().MarkHidden("refresh")
+
+	return cmd, nil
+}
+
+func shellRunE(mgr *manager.Manager) runEFunc {
+	return func(cmd *cobra.Command, args []string) error {
+		shell := os.Getenv("EVM_SHELL")
+		if !stringsContains(initShells, shell) {
+			return errors.New(
+				`Shell integration is not enabled. Add the output of ` +
+					`"evm init <shell>" to your shell's startup file.`,
+			)
+		}
+
+		out := cmd.OutOrStdout()
+
+		if refresh, _ := cmd.Flags().GetBool("refresh"); refresh {
+			_, err := fmt.Fprint(out, shellRefresh(mgr, shell))
+
+			return err
+		}
+
+		if unset, _ := cmd.Flags().GetBool("unset"); unset {
+			_, err := fmt.Fprint(out, shellUnset(shell, "EVM_VERSION"))
+
+			return err
+		}
+
+		if len(args) == 0 {
+			return errors.New("No version given.")
+		}
+
+		for _, version := range args {
+			if version == manager.SystemVersion {
+				continue
+			}
+
+			_, err := mgr.Get(cmd.Context(), version)
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err := fmt.Fprint(
+			out, shellExport(shell, "EVM_VERSION", strings.Join(args, " ")),
+		)
+
+		return err
+	}
+}
+
+// Returns shell code exporting the currently resolved versions, for use in
+// shell prompts. Programs are still resolved by shims on every call, so PATH
+// is left untouched.
+func shellRefresh(mgr *manager.Manager, shell string) string {
+	versions := strings.Join(mgr.CurrentVersions(), " ")
+	if versions == os.Getenv(shellResolvedEnv) {
+		return ""
+	}
+
+	if versions == "" {
+		return shellUnset(shell, shellResolvedEnv)
+	}
+
+	return shellExport(shell, shellResolvedEnv, versions)
+}
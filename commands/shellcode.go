@@ -0,0 +1,53 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	shShell   = "sh"
+	bashShell = "bash"
+	zshShell  = "zsh"
+	fishShell = "fish"
+)
+
+var initShells = []string{bashShell, zshShell, fishShell}
+
+func shellQuote(shell string, s string) string {
+	if shell == fishShell {
+		s = strings.ReplaceAll(s, `\`, `\\`)
+		return "'" + strings.ReplaceAll(s, "'", `\'`) + "'"
+	}
+
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func shellExport(shell string, name string, value string) string {
+	if shell == fishShell {
+		return fmt.Sprintf("set -gx %s %s\n", name, shellQuote(shell, value))
+	}
+
+	return fmt.Sprintf("export %s=%s\n", name, shellQuote(shell, value))
+}
+
+func shellExportList(shell string, name string, values []string) string {
+	if shell != fishShell {
+		return shellExport(shell, name, strings.Join(values, ":"))
+	}
+
+	quoted := make([]string, 0, len(values))
+	for _, v := range values {
+		quoted = append(quoted, shellQuote(shell, v))
+	}
+
+	return fmt.Sprintf("set -gx %s %s\n", name, strings.Join(quoted, " "))
+}
+
+func shellUnset(shell string, name string) string {
+	if shell == fishShell {
+		return fmt.Sprintf("set -e %s\n", name)
+	}
+
+	return fmt.Sprintf("unset %s\n", name)
+}
@@ -0,0 +1,233 @@
+package commands
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/jimeh/evm/manager"
+	"github.com/jimeh/go-render"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+func NewShims(mgr *manager.Manager) (*cobra.Command, error) {
+	cmd := &cobra.Command{
+		Use:               "shims",
+		Short:             "List shims and the versions which provide them",
+		Args:              cobra.ExactArgs(0),
+		SilenceUsage:      true,
+		ValidArgsFunction: noValidArgs,
+		RunE:              shimsRunE(mgr),
+	}
+
+	cmd.Flags().StringP(
+		"format", "f", "text", "output format, \"text\", \"yaml\", or \"json\"",
+	)
+	cmd.Flags().BoolP(
+		"check", "c", false, "report stale, outdated, and orphaned shims",
+	)
+
+	return cmd, nil
+}
+
+func shimsRunE(mgr *manager.Manager) runEFunc {
+	return func(cmd *cobra.Command, _ []string) error {
+		format := flagString(cmd, "format")
+
+		if check, _ := cmd.Flags().GetBool("check"); check {
+			return shimsCheck(cmd, mgr, format)
+		}
+
+		shims, err := mgr.Shims(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		excluded, err := mgr.ShimExclusions(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		output := &shimsOutput{Shims: shims, Excluded: excluded}
+
+		return render.Pretty(cmd.OutOrStdout(), format, output)
+	}
+}
+
+type shimsOutput struct {
+	Shims    []*manager.Shim          `yaml:"shims" json:"shims"`
+	Excluded []*manager.ShimExclusion `yaml:"excluded" json:"excluded"`
+}
+
+func (so *shimsOutput) String() string {
+	buf := &strings.Builder{}
+
+	for _, shim := range so.Shims {
+		if shim.Current {
+			buf.WriteString("* ")
+		} else {
+			buf.WriteString("  ")
+		}
+
+		buf.WriteString(shim.Name)
+		if shim.Orphaned {
+			buf.WriteString(" (orphaned)")
+		} else {
+			buf.WriteString(" (" + strings.Join(shim.Versions, ", ") + ")")
+		}
+
+		buf.WriteByte('\n')
+	}
+
+	if len(so.Excluded) > 0 {
+		buf.WriteString("\nExcluded:\n")
+		for _, ex := range so.Excluded {
+			buf.WriteString(
+				"  " + ex.Program + " (" + ex.Version + ", by " +
+					ex.Rule + ")\n",
+			)
+		}
+	}
+
+	return buf.String()
+}
+
+func shimsCheck(
+	cmd *cobra.Command,
+	mgr *manager.Manager,
+	format string,
+) error {
+	check, err := mgr.CheckShims(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	err = render.Pretty(cmd.OutOrStdout(), format, &shimsCheckOutput{check})
+	if err != nil {
+		return err
+	}
+
+	if len(check.Issues) > 0 {
+		return fmt.Errorf(
+			"Found %d shim problem(s), fix them with: evm rehash",
+			len(check.Issues),
+		)
+	}
+
+	return nil
+}
+
+type shimsCheckOutput struct {
+	*manager.ShimCheck `yaml:",inline"`
+}
+
+func (sco *shimsCheckOutput) String() string {
+	buf := &strings.Builder{}
+
+	if sco.ShimsOnPath {
+		buf.WriteString("Shims directory is on PATH: " + sco.ShimsDir + "\n")
+	} else {
+		buf.WriteString(
+			"Shims directory is NOT on PATH: " + sco.ShimsDir + "\n",
+		)
+	}
+
+	if sco.BinaryOnPath != "" {
+		buf.WriteString("evm is on PATH: " + sco.BinaryOnPath + "\n")
+	} else {
+		buf.WriteString("evm is NOT on PATH, shims use: " + sco.Binary + "\n")
+	}
+
+	buf.WriteString(
+		"\nChecked " + strconv.Itoa(sco.Shims) + " shim(s), found " +
+			strconv.Itoa(len(sco.Issues)) + " problem(s)\n",
+	)
+	for _, issue := range sco.Issues {
+		buf.WriteString("  " + issue.Program + ": " + issue.Problem + "\n")
+	}
+
+	return buf.String()
+}
+
+// Commands which never check for stale shims, as they run on every shim
+// execution or completion, or deal with shims themselves.
+var skipShimsStaleCheck = []string{
+	"exec",
+	"rehash",
+	"shims",
+	"help",
+	"completion",
+	cobra.ShellCompRequestCmd,
+	cobra.ShellCompNoDescRequestCmd,
+}
+
+// Commands which may prompt to rehash stale shims. All others only log a
+// warning, so scripted use never waits on stdin.
+var promptShimsRehash = []string{
+	"use",
+	"list",
+}
+
+// Detect shims which execute a different evm binary, typically after evm has
+// been moved or upgraded, and offer to rehash them. Problems are logged
+// rather than returned, so they never prevent the command from running.
+func healShims(cmd *cobra.Command, mgr *manager.Manager) error {
+	for c := cmd; c != nil; c = c.Parent() {
+		if stringsContains(skipShimsStaleCheck, c.Name()) {
+			return nil
+		}
+	}
+
+	stale, err := mgr.ShimsStale()
+	if err != nil {
+		log.Debug().Err(err).Msg("failed to check shims")
+		return nil
+	}
+	if !stale {
+		return nil
+	}
+
+	msg := "shims execute a different evm binary than " +
+		mgr.Config.Paths.Binary
+
+	if mgr.Config.Shims.AutoRehash {
+		log.Info().Msg(msg + ", rehashing")
+		return mgr.RehashAll(cmd.Context())
+	}
+
+	// Only prompt from commands which are run by hand, when a user is at the
+	// terminal and the output is not being captured.
+	if !stringsContains(promptShimsRehash, cmd.Name()) ||
+		cmd.Parent() != cmd.Root() ||
+		!isTerminal(os.Stdin) || !isTerminal(os.Stdout) ||
+		!isTerminal(os.Stderr) {
+		log.Warn().Msg(msg + ", update them with: evm rehash")
+		return nil
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "Shims execute a different evm binary "+
+		"than %s.\nRehash now? [y/N] ", mgr.Config.Paths.Binary)
+
+	answer, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return mgr.RehashAll(cmd.Context())
+	}
+
+	return nil
+}
+
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,246 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jimeh/evm/manager"
+	"github.com/spf13/cobra"
+)
+
+func NewTest(mgr *manager.Manager) (*cobra.Command, error) {
+	cmd := &cobra.Command{
+		Use:   "test [flags] [<test-file>...]",
+		Short: "Run ERT tests with each of multiple Emacs versions",
+		Long: `Run ERT tests in a clean batch Emacs for each selected version, and
+print a matrix of test results. Test files can be given as arguments or with
+--load.
+
+Results matching a test's expected result are reported as "pass", unexpected
+failures as "fail", and unexpected passes as "unexpected".`,
+		SilenceUsage:      true,
+		ValidArgsFunction: elispFileValidArgs,
+		RunE:              testRunE(mgr),
+	}
+
+	addMatrixFlags(cmd, mgr)
+	addJobsFlag(cmd)
+	cmd.Flags().StringArrayP(
+		"load", "l", nil, "elisp file to load before running tests",
+	)
+	cmd.Flags().StringArrayP(
+		"directory", "L", []string{"."}, "directory to add to load-path",
+	)
+	cmd.Flags().StringP(
+		"selector", "s", "t", "ERT test selector, as an elisp expression",
+	)
+	cmd.Flags().String(
+		"junit", "", "write a JUnit XML report to the given file",
+	)
+	cmd.Flags().String(
+		"json", "", "write a JSON report to the given file",
+	)
+
+	return cmd, nil
+}
+
+type testResult struct {
+	Version  string     `json:"version"`
+	ExitCode int        `json:"exit_code"`
+	Duration float64    `json:"duration"`
+	Error    string     `json:"error,omitempty"`
+	Output   string     `json:"-"`
+	Tests    []*ertTest `json:"tests,omitempty"`
+}
+
+func (tr *testResult) Failed() bool {
+	if tr.Error != "" {
+		return true
+	}
+	for _, t := range tr.Tests {
+		if t.Status == ertFail || t.Status == ertUnexpected {
+			return true
+		}
+	}
+
+	return false
+}
+
+func testRunE(mgr *manager.Manager) runEFunc {
+	return func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		versions, err := matrixVersions(cmd, mgr)
+		if err != nil {
+			return err
+		}
+
+		dirs, _ := cmd.Flags().GetStringArray("directory")
+		loads, _ := cmd.Flags().GetStringArray("load")
+		loads = append(loads, args...)
+		if len(loads) == 0 {
+			return errors.New("No test files given.")
+		}
+
+		emacsArgs := []string{"-Q", "--batch"}
+		for _, dir := range dirs {
+			emacsArgs = append(emacsArgs, "-L", dir)
+		}
+		for _, file := range loads {
+			emacsArgs = append(emacsArgs, "-l", file)
+		}
+		emacsArgs = append(emacsArgs, "--eval", fmt.Sprintf(
+			"(ert-run-tests-batch-and-exit (quote %s))",
+			flagString(cmd, "selector"),
+		))
+
+		results := make([]*testResult, len(versions))
+		runMatrix(len(versions), matrixJobs(cmd),
+			func(i int) {
+				res := &testResult{Version: versions[i]}
+				results[i] = res
+
+				c, err := mgr.Command(ctx, versions[i], "emacs", emacsArgs)
+				if err != nil {
+					res.Error = err.Error()
+					return
+				}
+
+				output, code, duration, err := runCaptured(c)
+				res.Output = string(output)
+				res.ExitCode = code
+				res.Duration = duration.Seconds()
+				if err != nil {
+					res.Error = err.Error()
+					return
+				}
+
+				res.Tests = parseERTOutput(output)
+				if code != 0 && !res.Failed() {
+					res.Error = fmt.Sprintf("Emacs exited with status %d", code)
+				}
+			},
+			func(int) {},
+		)
+
+		err = printTestMatrix(cmd.OutOrStdout(), results)
+		if err != nil {
+			return err
+		}
+
+		if path := flagString(cmd, "junit"); path != "" {
+			err = writeJUnitReport(path, results)
+			if err != nil {
+				return err
+			}
+		}
+
+		if path := flagString(cmd, "json"); path != "" {
+			b, err := json.MarshalIndent(
+				map[string]interface{}{"versions": results}, "", "  ",
+			)
+			if err != nil {
+				return err
+			}
+
+			err = os.WriteFile(path, append(b, '\n'), 0o644)
+			if err != nil {
+				return err
+			}
+		}
+
+		failed := 0
+		for _, res := range results {
+			if res.Failed() {
+				failed++
+			}
+		}
+		if failed > 0 {
+			return fmt.Errorf(
+				"Tests failed for %d of %d versions.", failed, len(results),
+			)
+		}
+
+		return nil
+	}
+}
+
+func printTestMatrix(w io.Writer, results []*testResult) error {
+	var names []string
+	statuses := map[string]map[string]string{}
+	for _, res := range results {
+		for _, t := range res.Tests {
+			if _, ok := statuses[t.Name]; !ok {
+				names = append(names, t.Name)
+				statuses[t.Name] = map[string]string{}
+			}
+			statuses[t.Name][res.Version] = t.Status
+		}
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	header := []string{"TEST"}
+	for _, res := range results {
+		header = append(header, res.Version)
+	}
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+
+	for _, name := range names {
+		row := []string{name}
+		for _, res := range results {
+			status := statuses[name][res.Version]
+			if status == "" {
+				status = "-"
+			}
+			row = append(row, status)
+		}
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+
+	err := tw.Flush()
+	if err != nil {
+		return err
+	}
+
+	for _, res := range results {
+		for _, t := range res.Tests {
+			if t.Status != ertFail && t.Status != ertUnexpected {
+				continue
+			}
+
+			fmt.Fprintf(w, "\n%s on Emacs %s", t.Name, res.Version)
+			if t.Location != "" {
+				fmt.Fprintf(w, " at %s", t.Location)
+			}
+			fmt.Fprintf(w, ": %s\n", t.Status)
+			if t.Condition != "" {
+				fmt.Fprintf(w, "  %s\n",
+					strings.ReplaceAll(t.Condition, "\n", "\n  "))
+			}
+		}
+
+		if res.Error != "" {
+			fmt.Fprintf(w, "\nEmacs %s: %s\n", res.Version, res.Error)
+			if res.Output != "" {
+				fmt.Fprintf(w, "  %s\n", strings.ReplaceAll(
+					strings.TrimRight(res.Output, "\n"), "\n", "\n  ",
+				))
+			}
+		}
+	}
+
+	return nil
+}
+
+func elispFileValidArgs(
+	_ *cobra.Command,
+	_ []string,
+	_ string,
+) ([]string, cobra.ShellCompDirective) {
+	return []string{"el"}, cobra.ShellCompDirectiveFilterFileExt
+}
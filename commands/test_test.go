@@ -0,0 +1,40 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jimeh/evm/manager"
+)
+
+func TestTestLoadFlag(t *testing.T) {
+	root, err := NewEvm(&manager.Manager{Config: &manager.Config{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmd, args, err := root.Find([]string{"test", "-l", "a-test.el"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cmd.Name() != "test" {
+		t.Fatalf("Find() = %s, want test", cmd.Name())
+	}
+
+	err = cmd.ParseFlags(append(args, "--load=b-test.el", "-lc-test.el"))
+	if err != nil {
+		t.Fatalf("ParseFlags() error = %v", err)
+	}
+
+	got, err := cmd.Flags().GetStringArray("load")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"a-test.el", "b-test.el", "c-test.el"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("load = %#v, want %#v", got, want)
+	}
+	if cmd.Flags().Lookup("log-level") == nil {
+		t.Error("log-level flag is not inherited")
+	}
+}
@@ -0,0 +1,42 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/jimeh/evm/manager"
+	"github.com/spf13/cobra"
+)
+
+func NewTrust(mgr *manager.Manager) (*cobra.Command, error) {
+	cmd := &cobra.Command{
+		Use: "trust [<dir>]",
+		Short: "Allow version files in a directory to select " +
+			"the Emacs version",
+		Aliases:           []string{"allow"},
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: dirValidArgs,
+		RunE:              trustRunE(mgr),
+	}
+
+	return cmd, nil
+}
+
+func trustRunE(mgr *manager.Manager) runEFunc {
+	return func(cmd *cobra.Command, args []string) error {
+		dir := "."
+		if len(args) > 0 {
+			dir = args[0]
+		}
+
+		paths, err := mgr.Trust(cmd.Context(), dir)
+		if err != nil {
+			return err
+		}
+
+		for _, path := range paths {
+			fmt.Fprintf(cmd.OutOrStdout(), "Trusted %s\n", path)
+		}
+
+		return nil
+	}
+}
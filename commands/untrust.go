@@ -0,0 +1,41 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/jimeh/evm/manager"
+	"github.com/spf13/cobra"
+)
+
+func NewUntrust(mgr *manager.Manager) (*cobra.Command, error) {
+	cmd := &cobra.Command{
+		Use:               "untrust [<dir>]",
+		Short:             "Revoke trust of version files in a directory",
+		Aliases:           []string{"deny", "disallow"},
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: dirValidArgs,
+		RunE:              untrustRunE(mgr),
+	}
+
+	return cmd, nil
+}
+
+func untrustRunE(mgr *manager.Manager) runEFunc {
+	return func(cmd *cobra.Command, args []string) error {
+		dir := "."
+		if len(args) > 0 {
+			dir = args[0]
+		}
+
+		paths, err := mgr.Untrust(cmd.Context(), dir)
+		if err != nil {
+			return err
+		}
+
+		for _, path := range paths {
+			fmt.Fprintf(cmd.OutOrStdout(), "Untrusted %s\n", path)
+		}
+
+		return nil
+	}
+}
@@ -9,10 +9,11 @@ import (
 
 func NewUse(mgr *manager.Manager) (*cobra.Command, error) {
 	cmd := &cobra.Command{
-		Use:               "use <version>",
-		Short:             "Switch to a specific version",
+		Use: "use <version> [<fallback-version>...]",
+		Short: "Switch to a specific version, optionally with " +
+			"fallback versions",
 		Aliases:           []string{"activate", "switch"},
-		Args:              cobra.ExactArgs(1),
+		Args:              cobra.MinimumNArgs(1),
 		ValidArgsFunction: useValidArgs(mgr),
 		RunE:              useRunE(mgr),
 	}
@@ -22,7 +23,7 @@ func NewUse(mgr *manager.Manager) (*cobra.Command, error) {
 
 func useRunE(mgr *manager.Manager) runEFunc {
 	return func(cmd *cobra.Command, args []string) error {
-		return mgr.Use(cmd.Context(), args[0])
+		return mgr.Use(cmd.Context(), args)
 	}
 }
 
@@ -32,17 +33,22 @@ func useValidArgs(mgr *manager.Manager) validArgsFunc {
 		args []string,
 		toComplete string,
 	) ([]string, cobra.ShellCompDirective) {
-		if len(args) > 0 {
-			return nil, cobra.ShellCompDirectiveNoFileComp
-		}
-
 		versions, err := mgr.List(cmd.Context())
 		if err != nil {
 			return nil, cobra.ShellCompDirectiveNoFileComp
 		}
 
 		var r []string
+		if !stringsContains(args, manager.SystemVersion) &&
+			strings.HasPrefix(manager.SystemVersion, toComplete) {
+			r = append(r, manager.SystemVersion)
+		}
+
 		for _, ver := range versions {
+			if stringsContains(args, ver.Version) {
+				continue
+			}
+
 			if toComplete == "" || strings.HasPrefix(ver.Version, toComplete) {
 				r = append(r, ver.Version)
 			}
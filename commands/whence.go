@@ -0,0 +1,93 @@
+package commands
+
+import (
+	"strings"
+
+	"github.com/jimeh/evm/manager"
+	"github.com/jimeh/go-render"
+	"github.com/spf13/cobra"
+)
+
+func NewWhence(mgr *manager.Manager) (*cobra.Command, error) {
+	cmd := &cobra.Command{
+		Use:               "whence <program>",
+		Short:             "List all Emacs versions which provide a program",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: execValidArgs(mgr),
+		RunE:              whenceRunE(mgr),
+	}
+
+	cmd.Flags().StringP(
+		"format", "f", "text", "output format, \"text\", \"yaml\", or \"json\"",
+	)
+
+	return cmd, nil
+}
+
+func whenceRunE(mgr *manager.Manager) runEFunc {
+	return func(cmd *cobra.Command, args []string) error {
+		format := flagString(cmd, "format")
+		program := args[0]
+
+		versions, err := mgr.FindBin(cmd.Context(), program)
+		if err != nil {
+			return err
+		}
+
+		current := mgr.CurrentFor(program).Versions
+		output := &whenceOutput{
+			Program:  program,
+			Versions: []*whenceOutputVersion{},
+		}
+
+		if bin, err := mgr.FindSystemBin(program); err == nil {
+			output.Versions = append(output.Versions, &whenceOutputVersion{
+				Version: manager.SystemVersion,
+				Current: stringsContains(current, manager.SystemVersion),
+				Path:    bin,
+			})
+		}
+
+		for _, ver := range versions {
+			bin, err := ver.FindBin(program)
+			if err != nil {
+				return err
+			}
+
+			output.Versions = append(output.Versions, &whenceOutputVersion{
+				Version: ver.Version,
+				Current: stringsContains(current, ver.Version),
+				Path:    bin,
+			})
+		}
+
+		return render.Pretty(cmd.OutOrStdout(), format, output)
+	}
+}
+
+type whenceOutput struct {
+	Program  string                 `yaml:"program" json:"program"`
+	Versions []*whenceOutputVersion `yaml:"versions" json:"versions"`
+}
+
+type whenceOutputVersion struct {
+	Version string `yaml:"version" json:"version"`
+	Current bool   `yaml:"current" json:"current"`
+	Path    string `yaml:"path" json:"path"`
+}
+
+func (wo *whenceOutput) String() string {
+	buf := &strings.Builder{}
+
+	for _, ver := range wo.Versions {
+		if ver.Current {
+			buf.WriteString("* ")
+		} else {
+			buf.WriteString("  ")
+		}
+
+		buf.WriteString(ver.Version + " (" + ver.Path + ")\n")
+	}
+
+	return buf.String()
+}
@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"strings"
+
+	"github.com/jimeh/evm/manager"
+	"github.com/jimeh/go-render"
+	"github.com/spf13/cobra"
+)
+
+func NewWhich(mgr *manager.Manager) (*cobra.Command, error) {
+	cmd := &cobra.Command{
+		Use:               "which <program>",
+		Short:             "Show the full path to the executable a shim runs",
+		Args:              cobra.ExactArgs(1),
+		SilenceUsage:      true,
+		ValidArgsFunction: execValidArgs(mgr),
+		RunE:              whichRunE(mgr),
+	}
+
+	cmd.Flags().StringP(
+		"format", "f", "text", "output format, \"text\", \"yaml\", or \"json\"",
+	)
+
+	return cmd, nil
+}
+
+func whichRunE(mgr *manager.Manager) runEFunc {
+	return func(cmd *cobra.Command, args []string) error {
+		format := flagString(cmd, "format")
+		program := args[0]
+
+		res, err := mgr.Which(cmd.Context(), program)
+		if err != nil {
+			return execError(cmd.Context(), mgr, program, err)
+		}
+
+		return render.Pretty(cmd.OutOrStdout(), format, &whichOutput{res})
+	}
+}
+
+type whichOutput struct {
+	*manager.Resolution `yaml:",inline"`
+}
+
+func (wo *whichOutput) String() string {
+	buf := &strings.Builder{}
+
+	buf.WriteString(wo.Bin + " (" + wo.Version)
+	if wo.SetBy != "" {
+		buf.WriteString(", set by " + wo.SetBy)
+	}
+	buf.WriteString(")\n")
+
+	return buf.String()
+}
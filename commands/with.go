@@ -0,0 +1,62 @@
+package commands
+
+import (
+	"errors"
+
+	"github.com/jimeh/evm/manager"
+	"github.com/spf13/cobra"
+)
+
+func NewWith(mgr *manager.Manager) (*cobra.Command, error) {
+	cmd := &cobra.Command{
+		Use:   "with <version> [--] <command> [<args>...]",
+		Short: "Run a command using a specific Emacs version",
+		Long: `Run any command with the environment of a specific Emacs version, as
+printed by "evm env". Shims used by the command and its child processes
+resolve to the given version, including programs with a version pinned by
+EVM_VERSION_<PROGRAM> or the "programs" config.
+
+Example:
+
+  evm with 28.2 -- make test`,
+		Args:              cobra.MinimumNArgs(2),
+		SilenceUsage:      true,
+		ValidArgsFunction: withValidArgs(mgr),
+		RunE:              withRunE(mgr),
+	}
+
+	// Flags after the version belong to the command being run.
+	cmd.Flags().SetInterspersed(false)
+
+	return cmd, nil
+}
+
+func withRunE(mgr *manager.Manager) runEFunc {
+	return func(cmd *cobra.Command, args []string) error {
+		// As flag parsing stops at the version, a "--" separator is passed
+		// through as a regular argument.
+		version, args := args[0], args[1:]
+		if args[0] == "--" {
+			args = args[1:]
+		}
+		if len(args) == 0 {
+			return errors.New("No command given.")
+		}
+
+		return mgr.ExecWith(cmd.Context(), version, args[0], args[1:])
+	}
+}
+
+func withValidArgs(mgr *manager.Manager) validArgsFunc {
+	return func(
+		cmd *cobra.Command,
+		args []string,
+		toComplete string,
+	) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveDefault
+		}
+
+		return useValidArgs(mgr)(cmd, args, toComplete)
+	}
+}
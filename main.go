@@ -4,7 +4,10 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"os/exec"
 	"os/signal"
+	"path/filepath"
+	"strings"
 	"syscall"
 
 	"github.com/jimeh/evm/commands"
@@ -12,12 +15,22 @@ import (
 )
 
 func main() {
-	mgr, err := manager.New(nil)
+	err := commands.SetupZerolog(nil)
 	if err != nil {
 		fatal(err)
 	}
 
-	cmd, err := commands.NewEvm(mgr)
+	// When invoked through a symlink or hardlink shim, execute the named
+	// program directly instead of running the evm CLI.
+	program := filepath.Base(os.Args[0])
+	isShim := !strings.HasPrefix(program, "evm")
+	if isShim && os.Getenv("EVM_ROOT") == "" {
+		if root, ok := shimRoot(os.Args[0]); ok {
+			_ = os.Setenv("EVM_ROOT", root)
+		}
+	}
+
+	mgr, err := manager.New(nil)
 	if err != nil {
 		fatal(err)
 	}
@@ -28,12 +41,40 @@ func main() {
 	)
 	defer cancel()
 
+	if isShim {
+		err = commands.ExecShim(ctx, mgr, program, os.Args[1:])
+		if err != nil {
+			fatal(err)
+		}
+	}
+
+	cmd, err := commands.NewEvm(mgr)
+	if err != nil {
+		fatal(err)
+	}
+
 	err = cmd.ExecuteContext(ctx)
 	if err != nil {
 		os.Exit(1)
 	}
 }
 
+// Returns the root directory owning the shim evm was invoked as, so shims
+// under a non-default root work without EVM_ROOT being set.
+func shimRoot(arg0 string) (string, bool) {
+	path, err := exec.LookPath(arg0)
+	if err != nil {
+		return "", false
+	}
+
+	path, err = filepath.Abs(path)
+	if err != nil {
+		return "", false
+	}
+
+	return manager.ShimRoot(path)
+}
+
 func fatal(err error) {
 	fmt.Fprintf(os.Stderr, "ERROR: %s\n", err)
 	os.Exit(1)
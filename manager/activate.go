@@ -0,0 +1,162 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Directories within a version's share directory which are linked into the
+// shared share directory.
+var shareDirs = []string{
+	"man",
+	"info",
+	"applications",
+	"icons",
+}
+
+// Point the active link and share links at the given version, or remove them
+// when version is empty or the "system" pseudo-version.
+func (m *Manager) activate(ctx context.Context, version string) error {
+	if !m.Config.Activation.Link() && !m.Config.Share {
+		return nil
+	}
+
+	var ver *Version
+	if version != "" && version != SystemVersion {
+		var err error
+		ver, err = m.Get(ctx, version)
+		if err != nil {
+			return err
+		}
+	}
+
+	if m.Config.Activation.Link() {
+		err := m.updateActiveLink(ver)
+		if err != nil {
+			return err
+		}
+	}
+
+	if m.Config.Share {
+		err := m.updateShareLinks(ver)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Bring the active link and share links in line with the global current
+// version.
+func (m *Manager) syncActivation(ctx context.Context) error {
+	var version string
+	for _, src := range m.Config.Current.Sources {
+		if src.Type == GlobalSource && len(src.Versions) > 0 {
+			version = src.Versions[0]
+		}
+	}
+
+	return m.activate(ctx, version)
+}
+
+func (m *Manager) updateActiveLink(ver *Version) error {
+	path := m.Config.Paths.Active
+	if ver == nil {
+		return removeSymlink(path)
+	}
+
+	return replaceSymlink(path, ver.Path)
+}
+
+func (m *Manager) updateShareLinks(ver *Version) error {
+	share := m.Config.Paths.Share
+
+	for _, name := range shareDirs {
+		path := filepath.Join(share, name)
+		if ver == nil {
+			err := removeSymlink(path)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		target := filepath.Join(ver.Path, "share", name)
+		f, err := os.Stat(target)
+		if err != nil || !f.IsDir() {
+			err = removeSymlink(path)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		err = os.MkdirAll(share, 0o755)
+		if err != nil {
+			return err
+		}
+
+		err = replaceSymlink(path, target)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Atomically point the symlink at path to target, using a relative target
+// where possible. Refuses to replace anything other than a symlink.
+func replaceSymlink(path, target string) error {
+	if rel, err := filepath.Rel(filepath.Dir(path), target); err == nil {
+		target = rel
+	}
+
+	lf, err := os.Lstat(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if err == nil {
+		if lf.Mode()&fs.ModeSymlink == 0 {
+			return fmt.Errorf(
+				"%wCannot update link, %s exists and is not a symlink",
+				Err, path,
+			)
+		}
+
+		if current, err := os.Readlink(path); err == nil && current == target {
+			return nil
+		}
+	}
+
+	log.Debug().Str("path", path).Str("target", target).Msg("updating link")
+
+	return linkAtomic(os.Symlink, target, path)
+}
+
+func removeSymlink(path string) error {
+	lf, err := os.Lstat(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	if lf.Mode()&fs.ModeSymlink == 0 {
+		return nil
+	}
+
+	log.Debug().Str("path", path).Msg("removing link")
+
+	return os.Remove(path)
+}
@@ -0,0 +1,62 @@
+package manager
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Write data to path by writing a temporary file in the same directory and
+// renaming it into place, so readers never see a partially written file.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Chmod(perm)
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+
+	return nil
+}
+
+// Atomically replace path with a symlink or hardlink to target, as created by
+// the given link function.
+func linkAtomic(
+	link func(oldname, newname string) error,
+	target string,
+	path string,
+) error {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	_ = f.Close()
+
+	err = os.Remove(tmp)
+	if err == nil {
+		err = link(target, tmp)
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+
+	// Renaming a hardlink over another link to the same file succeeds
+	// without removing it, so the temporary link is always cleaned up.
+	_ = os.Remove(tmp)
+
+	return err
+}
@@ -0,0 +1,221 @@
+package manager
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+)
+
+const (
+	ShimStaleBinary    = "stale binary path"
+	ShimStaleRoot      = "stale root path"
+	ShimOutdated       = "outdated"
+	ShimMissingProgram = "missing program"
+)
+
+type ShimIssue struct {
+	Program string `yaml:"program" json:"program"`
+	Path    string `yaml:"path" json:"path"`
+	Problem string `yaml:"problem" json:"problem"`
+}
+
+type ShimCheck struct {
+	Shims        int          `yaml:"shims" json:"shims"`
+	ShimsDir     string       `yaml:"shims_dir" json:"shims_dir"`
+	ShimsOnPath  bool         `yaml:"shims_on_path" json:"shims_on_path"`
+	Binary       string       `yaml:"binary" json:"binary"`
+	BinaryOnPath string       `yaml:"binary_on_path,omitempty" json:"binary_on_path,omitempty"`
+	Issues       []*ShimIssue `yaml:"issues" json:"issues"`
+}
+
+func (m *Manager) CheckShims(ctx context.Context) (*ShimCheck, error) {
+	versions, err := m.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	programs := m.Config.programVersions(versions)
+
+	shims, err := m.ListShims(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl, err := m.shimTemplate()
+	if err != nil {
+		return nil, err
+	}
+
+	check := &ShimCheck{
+		Shims:    len(shims),
+		ShimsDir: m.Config.Paths.Shims,
+		Binary:   m.Config.Paths.Binary,
+		Issues:   []*ShimIssue{},
+	}
+
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir != "" && filepath.Clean(dir) == filepath.Clean(check.ShimsDir) {
+			check.ShimsOnPath = true
+		}
+	}
+
+	if bin, err := exec.LookPath("evm"); err == nil {
+		check.BinaryOnPath = bin
+	}
+
+	for _, shim := range shims {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		name := filepath.Base(shim)
+		providers, ok := programs[name]
+		if !ok {
+			check.Issues = append(check.Issues, &ShimIssue{
+				Program: name, Path: shim, Problem: ShimMissingProgram,
+			})
+
+			continue
+		}
+
+		expected, err := renderShim(tmpl, &ShimData{
+			Config:  m.Config,
+			Program: name,
+			Version: providers[0],
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		problem, err := m.checkShim(shim, expected)
+		if err != nil {
+			return nil, err
+		}
+
+		if problem != "" {
+			check.Issues = append(check.Issues, &ShimIssue{
+				Program: name, Path: shim, Problem: problem,
+			})
+		}
+	}
+
+	sort.Slice(check.Issues, func(i, j int) bool {
+		return check.Issues[i].Program < check.Issues[j].Program
+	})
+
+	return check, nil
+}
+
+// Returns the problem with the shim at path, or an empty string if it is up
+// to date. The expected content is only used for script shims.
+func (m *Manager) checkShim(path string, expected []byte) (string, error) {
+	lf, err := os.Lstat(path)
+	if err != nil {
+		return "", err
+	}
+
+	binary := m.Config.Paths.Binary
+	mode := m.Config.Shims.Mode
+
+	if lf.Mode()&fs.ModeSymlink != 0 {
+		target, err := os.Readlink(path)
+		if err != nil {
+			return "", err
+		}
+
+		switch {
+		case target != binary:
+			return ShimStaleBinary, nil
+		case mode != SymlinkShims:
+			return ShimOutdated, nil
+		}
+
+		return "", nil
+	}
+
+	if bf, err := os.Stat(binary); err == nil && os.SameFile(lf, bf) {
+		if mode != HardlinkShims {
+			return ShimOutdated, nil
+		}
+
+		return "", nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	// Custom templates need not include the binary or root paths, in which
+	// case stale paths cannot be detected from the shim's content.
+	root := m.Config.Paths.Root
+	checkBinary := containsQuoted(expected, binary)
+	checkRoot := containsQuoted(expected, root)
+
+	switch {
+	case !bytes.HasPrefix(content, []byte("#!")),
+		checkBinary && !containsQuoted(content, binary):
+		return ShimStaleBinary, nil
+	case checkRoot && !containsQuoted(content, root):
+		return ShimStaleRoot, nil
+	case mode != ScriptShims || !bytes.Equal(content, expected):
+		return ShimOutdated, nil
+	}
+
+	return "", nil
+}
+
+// Reports if s appears in content either single quoted by shellQuote, or
+// double quoted as done by shims written by older versions of evm.
+func containsQuoted(content []byte, s string) bool {
+	return bytes.Contains(content, []byte(shellQuote(s))) ||
+		bytes.Contains(content, []byte(`"`+s+`"`))
+}
+
+// ShimsStale reports if existing shims execute a different evm binary than
+// the one currently running. Only a single shim is inspected, so this is
+// cheap enough to run on every command.
+func (m *Manager) ShimsStale() (bool, error) {
+	manifest, err := loadShimManifest(m.Config.Paths.Root)
+	if err != nil {
+		return false, err
+	}
+
+	var paths []string
+	for path := range manifest.shims {
+		if filepath.Dir(path) == m.Config.Paths.Shims {
+			paths = append(paths, path)
+		}
+	}
+	if len(paths) == 0 {
+		return false, nil
+	}
+	sort.Strings(paths)
+
+	tmpl, err := m.shimTemplate()
+	if err != nil {
+		return false, err
+	}
+	expected, err := renderShim(tmpl, &ShimData{
+		Config:  m.Config,
+		Program: filepath.Base(paths[0]),
+	})
+	if err != nil {
+		return false, err
+	}
+
+	problem, err := m.checkShim(paths[0], expected)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return problem == ShimStaleBinary, nil
+}
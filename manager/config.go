@@ -12,6 +12,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/rs/zerolog/log"
 	"github.com/sethvargo/go-envconfig"
 	"gopkg.in/yaml.v3"
 )
@@ -26,24 +27,106 @@ const (
 )
 
 type ConfigFile struct {
-	Paths ConfigFilePaths `yaml:"paths" json:"paths"`
+	Paths        ConfigFilePaths              `yaml:"paths" json:"paths"`
+	ToolVersions bool                         `yaml:"tool_versions" json:"tool_versions" env:"EVM_TOOL_VERSIONS,overwrite"`
+	Programs     map[string]string            `yaml:"programs" json:"programs"`
+	Shims        ConfigFileShims              `yaml:"shims" json:"shims"`
+	Versions     map[string]ConfigFileVersion `yaml:"versions" json:"versions"`
+	Activation   string                       `yaml:"activation" json:"activation" env:"EVM_ACTIVATION,overwrite"`
+	Share        bool                         `yaml:"share" json:"share" env:"EVM_SHARE,overwrite"`
+	Desktop      bool                         `yaml:"desktop" json:"desktop" env:"EVM_DESKTOP,overwrite"`
+}
+
+func newConfigFile() *ConfigFile {
+	return &ConfigFile{
+		ToolVersions: true,
+		Share:        true,
+	}
+}
+
+type ConfigFileShims struct {
+	Mode       string   `yaml:"mode" json:"mode" env:"EVM_SHIM_MODE,overwrite"`
+	Template   string   `yaml:"template" json:"template" env:"EVM_SHIM_TEMPLATE,overwrite"`
+	AutoRehash bool     `yaml:"auto_rehash" json:"auto_rehash" env:"EVM_AUTO_REHASH,overwrite"`
+	Include    []string `yaml:"include" json:"include" env:"EVM_SHIM_INCLUDE,overwrite"`
+	Exclude    []string `yaml:"exclude" json:"exclude" env:"EVM_SHIM_EXCLUDE,overwrite"`
+	Qualified  []string `yaml:"qualified" json:"qualified" env:"EVM_SHIM_QUALIFIED,overwrite"`
+}
+
+type ConfigFileVersion struct {
+	Include []string          `yaml:"include" json:"include"`
+	Exclude []string          `yaml:"exclude" json:"exclude"`
+	Env     map[string]string `yaml:"env" json:"env"`
 }
 
 type ConfigFilePaths struct {
 	Shims    string `yaml:"shims" json:"shims" env:"EVM_SHIMS,overwrite"`
 	Sources  string `yaml:"sources" json:"sources"  env:"EVM_SOURCES,overwrite"`
 	Versions string `yaml:"versions" json:"versions" env:"EVM_VERSIONS,overwrite"`
+	Active   string `yaml:"active" json:"active" env:"EVM_ACTIVE,overwrite"`
+	Share    string `yaml:"share" json:"share" env:"EVM_SHARE_DIR,overwrite"`
 }
 
 type Config struct {
-	Mode    Mode          `yaml:"mode" json:"mode"`
-	Current CurrentConfig `yaml:"current" json:"current"`
-	Paths   PathsConfig   `yaml:"paths" json:"paths"`
+	Mode         Mode                      `yaml:"mode" json:"mode"`
+	Current      CurrentConfig             `yaml:"current" json:"current"`
+	Paths        PathsConfig               `yaml:"paths" json:"paths"`
+	ToolVersions bool                      `yaml:"tool_versions" json:"tool_versions"`
+	Programs     map[string]*CurrentConfig `yaml:"programs,omitempty" json:"programs,omitempty"`
+	Shims        ShimsConfig               `yaml:"shims" json:"shims"`
+	Versions     map[string]*VersionConfig `yaml:"versions,omitempty" json:"versions,omitempty"`
+	Activation   Activation                `yaml:"activation" json:"activation"`
+	Share        bool                      `yaml:"share" json:"share"`
+	Desktop      bool                      `yaml:"desktop" json:"desktop"`
+
+	projectSources map[string][]*CurrentSource
 }
 
 type CurrentConfig struct {
-	Version string `yaml:"version" json:"version"`
-	SetBy   string `yaml:"set_by,omitempty" json:"set_by,omitempty"`
+	Version  string   `yaml:"version" json:"version"`
+	Versions []string `yaml:"versions,omitempty" json:"versions,omitempty"`
+	SetBy    string   `yaml:"set_by,omitempty" json:"set_by,omitempty"`
+
+	Sources []*CurrentSource `yaml:"-" json:"-"`
+}
+
+type ShimMode string
+
+const (
+	ScriptShims   ShimMode = "script"
+	SymlinkShims  ShimMode = "symlink"
+	HardlinkShims ShimMode = "hardlink"
+)
+
+type ShimsConfig struct {
+	Mode       ShimMode `yaml:"mode" json:"mode"`
+	Template   string   `yaml:"template,omitempty" json:"template,omitempty"`
+	AutoRehash bool     `yaml:"auto_rehash" json:"auto_rehash"`
+	Include    []string `yaml:"include,omitempty" json:"include,omitempty"`
+	Exclude    []string `yaml:"exclude,omitempty" json:"exclude,omitempty"`
+	Qualified  []string `yaml:"qualified,omitempty" json:"qualified,omitempty"`
+}
+
+type VersionConfig struct {
+	Include []string          `yaml:"include,omitempty" json:"include,omitempty"`
+	Exclude []string          `yaml:"exclude,omitempty" json:"exclude,omitempty"`
+	Env     map[string]string `yaml:"env,omitempty" json:"env,omitempty"`
+}
+
+type Activation string
+
+const (
+	ShimsActivation Activation = "shims"
+	LinkActivation  Activation = "link"
+	BothActivation  Activation = "both"
+)
+
+func (a Activation) Shims() bool {
+	return a == ShimsActivation || a == BothActivation
+}
+
+func (a Activation) Link() bool {
+	return a == LinkActivation || a == BothActivation
 }
 
 type PathsConfig struct {
@@ -52,6 +135,8 @@ type PathsConfig struct {
 	Shims    string `yaml:"shims" json:"shims"`
 	Sources  string `yaml:"sources" json:"sources"`
 	Versions string `yaml:"versions" json:"versions"`
+	Active   string `yaml:"active" json:"active"`
+	Share    string `yaml:"share" json:"share"`
 }
 
 func NewConfig() (*Config, error) {
@@ -70,12 +155,18 @@ func NewConfig() (*Config, error) {
 	}
 
 	conf := &Config{
-		Mode: mode,
+		Mode:       mode,
+		Activation: ShimsActivation,
+		Shims: ShimsConfig{
+			Mode: ScriptShims,
+		},
 		Paths: PathsConfig{
 			Root:     defaultRoot,
 			Shims:    "$EVM_ROOT/shims",
 			Sources:  "$EVM_ROOT/sources",
 			Versions: "$EVM_ROOT/versions",
+			Active:   "$EVM_ROOT/active",
+			Share:    "$EVM_ROOT/share",
 		},
 	}
 
@@ -102,6 +193,21 @@ func NewConfig() (*Config, error) {
 	if err != nil {
 		return nil, err
 	}
+	conf.Paths.Active, err = conf.normalizePath(conf.Paths.Active)
+	if err != nil {
+		return nil, err
+	}
+	conf.Paths.Share, err = conf.normalizePath(conf.Paths.Share)
+	if err != nil {
+		return nil, err
+	}
+
+	if conf.Shims.Template != "" {
+		conf.Shims.Template, err = conf.normalizePath(conf.Shims.Template)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	conf.Paths.Binary, err = os.Executable()
 	if err != nil {
@@ -116,33 +222,106 @@ func NewConfig() (*Config, error) {
 	return conf, nil
 }
 
-const currentFileName = "current"
+const (
+	currentFileName = "current"
+	versionEnvName  = "EVM_VERSION"
+)
 
-func (conf *Config) PopulateCurrent() error {
-	if v := os.Getenv("EVM_VERSION"); v != "" {
-		conf.Current.Version = strings.TrimSpace(v)
-		conf.Current.SetBy = "EVM_VERSION environment variable"
+type CurrentSourceType string
+
+const (
+	EnvSource     CurrentSourceType = "env"
+	ProjectSource CurrentSourceType = "project"
+	GlobalSource  CurrentSourceType = "global"
+)
 
-		return nil
+type CurrentSource struct {
+	Type     CurrentSourceType `yaml:"type" json:"type"`
+	Source   string            `yaml:"source" json:"source"`
+	Exists   bool              `yaml:"exists" json:"exists"`
+	Versions []string          `yaml:"versions,omitempty" json:"versions,omitempty"`
+	Ignored  string            `yaml:"ignored,omitempty" json:"ignored,omitempty"`
+	Selected bool              `yaml:"selected" json:"selected"`
+}
+
+func (cs *CurrentSource) usable() bool {
+	return cs.Ignored == "" && len(cs.Versions) > 0
+}
+
+func (conf *Config) PopulateCurrent() error {
+	v, ok := os.LookupEnv(versionEnvName)
+	sources := []*CurrentSource{{
+		Type:     EnvSource,
+		Source:   versionEnvName + " environment variable",
+		Exists:   ok,
+		Versions: parseVersionList(v),
+	}}
+
+	// The working directory may have been removed, in which case there are
+	// no project version files to consider.
+	if wd, err := os.Getwd(); err != nil {
+		log.Debug().Err(err).Msg("skipping project version files")
+	} else {
+		project, err := conf.projectVersionSources(wd)
+		if err != nil {
+			return err
+		}
+		sources = append(sources, project...)
 	}
 
 	currentFile := filepath.Join(conf.Paths.Root, currentFileName)
+	global := &CurrentSource{Type: GlobalSource, Source: currentFile}
 	b, err := os.ReadFile(currentFile)
-	if err != nil {
-		if errors.Is(err, fs.ErrNotExist) {
-			return nil
-		}
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
 		return err
 	}
+	if err == nil {
+		global.Exists = true
+		global.Versions = parseVersionList(string(b))
+	}
+	sources = append(sources, global)
+
+	conf.Current.Sources = sources
+	for _, src := range sources {
+		if src.usable() {
+			src.Selected = true
+			conf.Current.set(src.Versions, src.Source)
 
-	if len(b) > 0 {
-		conf.Current.Version = strings.TrimSpace(string(b))
-		conf.Current.SetBy = currentFile
+			break
+		}
+
+		if src.Ignored == ignoredUntrusted {
+			log.Warn().Str("path", src.Source).Msgf(
+				"ignoring untrusted version file, allow it with: "+
+					"evm trust %s",
+				filepath.Dir(src.Source),
+			)
+		}
 	}
 
 	return nil
 }
 
+func (cc *CurrentConfig) set(versions []string, setBy string) {
+	cc.Version = versions[0]
+	cc.Versions = versions
+	cc.SetBy = setBy
+}
+
+func (conf *Config) isCurrent(version string) bool {
+	return stringsContain(conf.Current.Versions, version)
+}
+
+func stringsContain(haystack []string, needle string) bool {
+	for _, s := range haystack {
+		if s == needle {
+			return true
+		}
+	}
+
+	return false
+}
+
 var configFileNames = []string{
 	"config.yaml",
 	"config.yml",
@@ -169,7 +348,7 @@ func (c *Config) load() error {
 		break
 	}
 
-	cf := &ConfigFile{}
+	cf := newConfigFile()
 	if path != "" {
 		var err error
 		cf, err = c.loadConfigFile(path)
@@ -192,6 +371,103 @@ func (c *Config) load() error {
 	if cf.Paths.Versions != "" {
 		c.Paths.Versions = cf.Paths.Versions
 	}
+	if cf.Paths.Active != "" {
+		c.Paths.Active = cf.Paths.Active
+	}
+	if cf.Paths.Share != "" {
+		c.Paths.Share = cf.Paths.Share
+	}
+	c.Share = cf.Share
+	c.Desktop = cf.Desktop
+
+	switch Activation(cf.Activation) {
+	case "":
+	case ShimsActivation, LinkActivation, BothActivation:
+		c.Activation = Activation(cf.Activation)
+	default:
+		return fmt.Errorf(
+			`%wactivation "%s" is not one of "%s", "%s", or "%s"`,
+			ErrConfig, cf.Activation,
+			ShimsActivation, LinkActivation, BothActivation,
+		)
+	}
+	c.ToolVersions = cf.ToolVersions
+
+	if cf.Shims.Template != "" {
+		c.Shims.Template = cf.Shims.Template
+	}
+	c.Shims.AutoRehash = cf.Shims.AutoRehash
+	c.Shims.Include = cf.Shims.Include
+	c.Shims.Exclude = cf.Shims.Exclude
+	c.Shims.Qualified = cf.Shims.Qualified
+
+	err = validateQualifiedPatterns(c.Shims.Qualified)
+	if err != nil {
+		return err
+	}
+
+	err = validatePatterns("shims.include", c.Shims.Include)
+	if err != nil {
+		return err
+	}
+	err = validatePatterns("shims.exclude", c.Shims.Exclude)
+	if err != nil {
+		return err
+	}
+
+	for version, vc := range cf.Versions {
+		prefix := "versions." + version
+		err = validatePatterns(prefix+".include", vc.Include)
+		if err != nil {
+			return err
+		}
+		err = validatePatterns(prefix+".exclude", vc.Exclude)
+		if err != nil {
+			return err
+		}
+		for name := range vc.Env {
+			if name == "" || strings.ContainsAny(name, "= \t\n") {
+				return fmt.Errorf(
+					`%w%s.env variable name "%s" is invalid`,
+					ErrConfig, prefix, name,
+				)
+			}
+		}
+
+		if c.Versions == nil {
+			c.Versions = map[string]*VersionConfig{}
+		}
+		c.Versions[version] = &VersionConfig{
+			Include: vc.Include,
+			Exclude: vc.Exclude,
+			Env:     vc.Env,
+		}
+	}
+
+	switch ShimMode(cf.Shims.Mode) {
+	case "":
+	case ScriptShims, SymlinkShims, HardlinkShims:
+		c.Shims.Mode = ShimMode(cf.Shims.Mode)
+	default:
+		return fmt.Errorf(
+			`%wshim mode "%s" is not one of "%s", "%s", or "%s"`,
+			ErrConfig, cf.Shims.Mode,
+			ScriptShims, SymlinkShims, HardlinkShims,
+		)
+	}
+
+	for program, v := range cf.Programs {
+		versions := parseVersionList(v)
+		if len(versions) == 0 {
+			continue
+		}
+
+		if c.Programs == nil {
+			c.Programs = map[string]*CurrentConfig{}
+		}
+		c.Programs[program] = &CurrentConfig{}
+		c.Programs[program].set(versions, path)
+	}
 
 	return nil
 }
@@ -206,7 +482,7 @@ func (c *Config) loadConfigFile(path string) (*ConfigFile, error) {
 		return nil, err
 	}
 
-	cf := &ConfigFile{}
+	cf := newConfigFile()
 
 	buf := bytes.NewBuffer(content)
 	switch filepath.Ext(path) {
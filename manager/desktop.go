@@ -0,0 +1,216 @@
+package manager
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	desktopFilePrefix = "evm-emacs-"
+	desktopFileSuffix = ".desktop"
+	desktopVersionKey = "X-Evm-Version"
+	desktopProgram    = "emacs"
+)
+
+// Icons looked for within a version's directory, in order of preference.
+var desktopIcons = []string{
+	"share/icons/hicolor/scalable/apps/emacs.svg",
+	"share/icons/hicolor/128x128/apps/emacs.png",
+	"share/icons/hicolor/48x48/apps/emacs.png",
+}
+
+func (m *Manager) desktopDir() (string, error) {
+	if v := os.Getenv("XDG_DATA_HOME"); v != "" {
+		return filepath.Join(v, "applications"), nil
+	}
+
+	if m.Config.Mode == System {
+		return filepath.Join(
+			string(os.PathSeparator), "usr", "local", "share", "applications",
+		), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(home, ".local", "share", "applications"), nil
+}
+
+// SyncDesktopEntries writes a desktop entry for each installed version which
+// provides Emacs, and removes entries for versions which no longer exist.
+func (m *Manager) SyncDesktopEntries(
+	ctx context.Context,
+) (written []string, removed []string, err error) {
+	dir, err := m.desktopDir()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	versions, err := m.List(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keep := map[string]bool{}
+	for _, ver := range versions {
+		if _, err := ver.FindBin(desktopProgram); err != nil {
+			continue
+		}
+
+		path := filepath.Join(
+			dir, desktopFilePrefix+ver.Version+desktopFileSuffix,
+		)
+		keep[path] = true
+
+		content := m.desktopEntry(ver)
+		if b, err := os.ReadFile(path); err == nil && bytes.Equal(b, content) {
+			continue
+		}
+
+		log.Debug().Str("path", path).Msg("writing desktop entry")
+		err = writeFileAtomic(path, content, 0o644)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		written = append(written, path)
+	}
+
+	removed, err = m.removeDesktopEntries(dir, keep)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return written, removed, nil
+}
+
+// CleanDesktopEntries removes all desktop entries written by evm.
+func (m *Manager) CleanDesktopEntries(_ context.Context) ([]string, error) {
+	dir, err := m.desktopDir()
+	if err != nil {
+		return nil, err
+	}
+
+	return m.removeDesktopEntries(dir, nil)
+}
+
+func (m *Manager) removeDesktopEntries(
+	dir string,
+	keep map[string]bool,
+) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var removed []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, desktopFilePrefix) ||
+			!strings.HasSuffix(name, desktopFileSuffix) {
+			continue
+		}
+
+		path := filepath.Join(dir, name)
+		if keep[path] {
+			continue
+		}
+
+		// Only remove files carrying evm's marker key.
+		b, err := os.ReadFile(path)
+		if err != nil ||
+			!bytes.Contains(b, []byte("\n"+desktopVersionKey+"=")) {
+			continue
+		}
+
+		log.Debug().Str("path", path).Msg("removing desktop entry")
+		err = os.Remove(path)
+		if err != nil {
+			return nil, err
+		}
+
+		removed = append(removed, path)
+	}
+
+	return removed, nil
+}
+
+func (m *Manager) desktopEntry(ver *Version) []byte {
+	icon := desktopProgram
+	for _, name := range desktopIcons {
+		path := filepath.Join(ver.Path, name)
+		if _, err := os.Stat(path); err == nil {
+			icon = path
+			break
+		}
+	}
+
+	exec := strings.Join([]string{
+		"env",
+		desktopQuote("EVM_ROOT=" + m.Config.Paths.Root),
+		desktopQuote("EVM_VERSION=" + ver.Version),
+		// Also override any version pinned for Emacs itself.
+		desktopQuote(programEnvName(desktopProgram) + "=" + ver.Version),
+		desktopQuote(m.Config.Paths.Binary),
+		"exec",
+		desktopProgram,
+		"%F",
+	}, " ")
+
+	var buf bytes.Buffer
+	buf.WriteString("[Desktop Entry]\n")
+	buf.WriteString("Type=Application\n")
+	buf.WriteString("Name=Emacs " + ver.Version + " (evm)\n")
+	buf.WriteString("GenericName=Text Editor\n")
+	buf.WriteString("Comment=Edit text with Emacs " + ver.Version + "\n")
+	buf.WriteString("Exec=" + exec + "\n")
+	buf.WriteString("Icon=" + icon + "\n")
+	buf.WriteString("Terminal=false\n")
+	buf.WriteString("Categories=Development;TextEditor;\n")
+	buf.WriteString("MimeType=text/english;text/plain;\n")
+	buf.WriteString("StartupWMClass=Emacs\n")
+	buf.WriteString(desktopVersionKey + "=" + ver.Version + "\n")
+
+	return buf.Bytes()
+}
+
+// Quote an argument for the Exec key of a desktop entry, as described by the
+// Desktop Entry Specification. Literal "%" characters are escaped as "%%", as
+// they would otherwise start a field code.
+func desktopQuote(s string) string {
+	s = strings.ReplaceAll(s, "%", "%%")
+	if !strings.ContainsAny(s, " \t\n\"'\\><~|&;$*?#()`") {
+		return s
+	}
+
+	var buf strings.Builder
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"', '`', '$', '\\':
+			buf.WriteByte('\\')
+		}
+		buf.WriteRune(r)
+	}
+	buf.WriteByte('"')
+
+	// Backslashes must be escaped again at the string value level.
+	return strings.ReplaceAll(buf.String(), `\`, `\\`)
+}
@@ -0,0 +1,33 @@
+package manager
+
+import "testing"
+
+func TestDesktopQuote(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want string
+	}{
+		{name: "plain", s: "/usr/bin/evm", want: "/usr/bin/evm"},
+		{name: "space", s: "/opt/my evm/evm", want: `"/opt/my evm/evm"`},
+		{name: "double quote", s: `a"b`, want: `"a\\"b"`},
+		{name: "backslash", s: `a\b`, want: `"a\\\\b"`},
+		{name: "dollar", s: "a$b", want: `"a\\$b"`},
+		{name: "percent", s: "/home/100%/evm", want: "/home/100%%/evm"},
+		{
+			name: "percent and space",
+			s:    "/my 100%/evm",
+			want: `"/my 100%%/evm"`,
+		},
+		{name: "field code", s: "EVM_ROOT=%f", want: "EVM_ROOT=%%f"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := desktopQuote(tt.s)
+
+			if got != tt.want {
+				t.Errorf("desktopQuote() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
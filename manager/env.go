@@ -0,0 +1,204 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+type EnvVar struct {
+	Name  string `yaml:"name" json:"name"`
+	Value string `yaml:"value" json:"value"`
+}
+
+// VersionEnv returns the environment variables ExecVersion sets when executing
+// a program from the given version.
+func (m *Manager) VersionEnv(
+	ctx context.Context,
+	version string,
+) ([]*EnvVar, error) {
+	var binDirs []string
+	if version != SystemVersion {
+		ver, err := m.Get(ctx, version)
+		if err != nil {
+			return nil, err
+		}
+		binDirs = append(binDirs, ver.BinDir)
+	}
+
+	return m.versionEnv(version, binDirs, true), nil
+}
+
+// ExecWith executes an arbitrary command with the environment of the given
+// version, as returned by VersionEnv.
+func (m *Manager) ExecWith(
+	ctx context.Context,
+	version string,
+	command string,
+	args []string,
+) error {
+	res, env, err := m.resolveCommand(ctx, version, command)
+	if err != nil {
+		return err
+	}
+
+	return m.execResolution(ctx, res, env, args)
+}
+
+// Command returns an *exec.Cmd which runs an arbitrary command with the
+// environment of the given version, as a child process.
+func (m *Manager) Command(
+	ctx context.Context,
+	version string,
+	command string,
+	args []string,
+) (*exec.Cmd, error) {
+	res, env, err := m.resolveCommand(ctx, version, command)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.CommandContext(ctx, res.Bin, args...)
+	cmd.Env = environ(env)
+
+	return cmd, nil
+}
+
+func (m *Manager) resolveCommand(
+	ctx context.Context,
+	version string,
+	command string,
+) (*Resolution, []*EnvVar, error) {
+	res := &Resolution{Program: command, Version: version}
+	if version != SystemVersion {
+		ver, err := m.Get(ctx, version)
+		if err != nil {
+			return nil, nil, err
+		}
+		res.BinDirs = []string{ver.BinDir}
+	}
+
+	env := m.versionEnv(version, res.BinDirs, true)
+
+	var err error
+	res.Bin, err = lookPath(command, envValue(env, "PATH"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return res, env, nil
+}
+
+// versionEnv returns PATH with binDirs prepended, per-version variables from
+// config, and EVM_VERSION when pin is true. Pinning also sets every
+// EVM_VERSION_<PROGRAM> variable in use, so programs with their own pinned
+// version run from the given version too.
+func (m *Manager) versionEnv(
+	version string,
+	binDirs []string,
+	pin bool,
+) []*EnvVar {
+	var env []*EnvVar
+
+	if len(binDirs) > 0 {
+		path := strings.Join(binDirs, ":")
+		if v := os.Getenv("PATH"); v != "" {
+			path += ":" + v
+		}
+		env = append(env, &EnvVar{Name: "PATH", Value: path})
+	}
+
+	if pin {
+		env = append(env, &EnvVar{Name: versionEnvName, Value: version})
+		for _, name := range m.Config.programEnvNames() {
+			env = append(env, &EnvVar{Name: name, Value: version})
+		}
+	}
+
+	if vc, ok := m.Config.Versions[version]; ok {
+		names := make([]string, 0, len(vc.Env))
+		for name := range vc.Env {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			env = append(env, &EnvVar{Name: name, Value: vc.Env[name]})
+		}
+	}
+
+	return env
+}
+
+// environ returns the current process environment with env applied.
+func environ(env []*EnvVar) []string {
+	r := os.Environ()
+
+	for _, e := range env {
+		entry := e.Name + "=" + e.Value
+		found := false
+		for i := range r {
+			if strings.HasPrefix(r[i], e.Name+"=") {
+				r[i] = entry
+				found = true
+			}
+		}
+		if !found {
+			r = append(r, entry)
+		}
+	}
+
+	return r
+}
+
+func envValue(env []*EnvVar, name string) string {
+	for _, e := range env {
+		if e.Name == name {
+			return e.Value
+		}
+	}
+
+	return os.Getenv(name)
+}
+
+// lookPath finds an executable like exec.LookPath, but searches the given PATH
+// value instead of the current process' PATH.
+func lookPath(name string, path string) (string, error) {
+	if strings.Contains(name, "/") {
+		if isExecutable(name) {
+			return name, nil
+		}
+
+		return "", fmt.Errorf(
+			`%wExecutable "%s" not found`, ErrBinNotFound, name,
+		)
+	}
+
+	for _, dir := range filepath.SplitList(path) {
+		if dir == "" {
+			dir = "."
+		}
+
+		p := filepath.Join(dir, name)
+		if isExecutable(p) {
+			return p, nil
+		}
+	}
+
+	return "", fmt.Errorf(
+		`%wExecutable "%s" not found in PATH`, ErrBinNotFound, name,
+	)
+}
+
+func isExecutable(path string) bool {
+	f, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+
+	return f.Mode().IsRegular() && f.Mode().Perm()&0111 == 0111
+}
@@ -0,0 +1,105 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+var ErrLocked = fmt.Errorf("%w", Err)
+
+const (
+	lockFileName     = "evm.lock"
+	lockTimeout      = 30 * time.Second
+	lockPollInterval = 100 * time.Millisecond
+)
+
+// lock acquires an exclusive lock on $EVM_ROOT, which is held while shims and
+// the current file are modified. The returned function releases the lock.
+func (m *Manager) lock(ctx context.Context) (func(), error) {
+	err := os.MkdirAll(m.Config.Paths.Root, 0o755)
+	if err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(m.Config.Paths.Root, lockFileName)
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, lockTimeout)
+	defer cancel()
+
+	waiting := false
+	for {
+		locked, err := tryLockFile(f)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		if locked {
+			break
+		}
+
+		holder := lockHolder(path)
+		if !waiting {
+			log.Info().Str("path", path).Str("pid", holder).
+				Msg("waiting for lock held by another evm process")
+			waiting = true
+		}
+
+		select {
+		case <-ctx.Done():
+			f.Close()
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return nil, fmt.Errorf(
+					"%wTimed out after %s waiting for lock %s "+
+						"held by process %s",
+					ErrLocked, lockTimeout, path, holder,
+				)
+			}
+			return nil, ctx.Err()
+		case <-time.After(lockPollInterval):
+		}
+	}
+
+	log.Debug().Str("path", path).Msg("acquired lock")
+
+	err = f.Truncate(0)
+	if err == nil {
+		_, err = f.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	}
+	if err != nil {
+		_ = unlockFile(f)
+		f.Close()
+		return nil, err
+	}
+
+	return func() {
+		log.Debug().Str("path", path).Msg("releasing lock")
+		_ = f.Truncate(0)
+		_ = unlockFile(f)
+		f.Close()
+	}, nil
+}
+
+func lockHolder(path string) string {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "unknown"
+	}
+
+	if pid := strings.TrimSpace(string(b)); pid != "" {
+		return pid
+	}
+
+	return "unknown"
+}
@@ -0,0 +1,15 @@
+//go:build !unix
+
+package manager
+
+import "os"
+
+// File locking is not supported on this platform, so the lock file only
+// records the process which holds it.
+func tryLockFile(_ *os.File) (bool, error) {
+	return true, nil
+}
+
+func unlockFile(_ *os.File) error {
+	return nil
+}
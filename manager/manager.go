@@ -1,13 +1,9 @@
 package manager
 
 import (
-	"bytes"
 	"context"
 	"errors"
 	"fmt"
-	"html/template"
-	"io/fs"
-	"os"
 	"path/filepath"
 	"strings"
 	"syscall"
@@ -43,10 +39,18 @@ func (m *Manager) CurrentVersion() string {
 	return m.Config.Current.Version
 }
 
+func (m *Manager) CurrentVersions() []string {
+	return m.Config.Current.Versions
+}
+
 func (m *Manager) CurrentSetBy() string {
 	return m.Config.Current.SetBy
 }
 
+func (m *Manager) CurrentSources() []*CurrentSource {
+	return m.Config.Current.Sources
+}
+
 func (m *Manager) List(ctx context.Context) ([]*Version, error) {
 	return newVersions(ctx, m.Config)
 }
@@ -55,27 +59,44 @@ func (m *Manager) Get(ctx context.Context, version string) (*Version, error) {
 	return newVersion(ctx, m.Config, version)
 }
 
-func (m *Manager) Use(ctx context.Context, version string) error {
-	log.Debug().Str("version", version).Msg("use version")
+func (m *Manager) Use(ctx context.Context, versions []string) error {
+	log.Debug().Strs("versions", versions).Msg("use versions")
+
+	if len(versions) == 0 {
+		return fmt.Errorf("%wversion cannot be empty", ErrVersion)
+	}
 
-	ver, err := m.Get(ctx, version)
+	unlock, err := m.lock(ctx)
 	if err != nil {
 		return err
 	}
+	defer unlock()
 
-	currentFile := filepath.Join(m.Config.Paths.Root, currentFileName)
+	var vers []*Version
+	for _, version := range versions {
+		if version == SystemVersion {
+			continue
+		}
 
-	log.Debug().
-		Str("path", currentFile).
-		Str("content", ver.Version).
-		Msg("updating current file")
+		ver, err := m.Get(ctx, version)
+		if err != nil {
+			return err
+		}
+
+		vers = append(vers, ver)
+	}
+
+	err = m.writeCurrentFile(strings.Join(versions, " "))
+	if err != nil {
+		return err
+	}
 
-	err = os.WriteFile(currentFile, []byte(ver.Version), 0o644)
+	err = m.activate(ctx, versions[0])
 	if err != nil {
 		return err
 	}
 
-	err = m.rehashVersions(ctx, false, []*Version{ver})
+	err = m.rehashVersions(ctx, false, vers)
 	if err != nil {
 		return err
 	}
@@ -83,12 +104,41 @@ func (m *Manager) Use(ctx context.Context, version string) error {
 	return nil
 }
 
+func (m *Manager) writeCurrentFile(version string) error {
+	currentFile := filepath.Join(m.Config.Paths.Root, currentFileName)
+
+	log.Debug().
+		Str("path", currentFile).
+		Str("content", version).
+		Msg("updating current file")
+
+	return writeFileAtomic(currentFile, []byte(version), 0o644)
+}
+
 func (m *Manager) RehashAll(ctx context.Context) error {
+	unlock, err := m.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	err = m.syncActivation(ctx)
+	if err != nil {
+		return err
+	}
+
 	versions, err := m.List(ctx)
 	if err != nil {
 		return err
 	}
 
+	if m.Config.Desktop {
+		_, _, err = m.SyncDesktopEntries(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
 	return m.rehashVersions(ctx, true, versions)
 }
 
@@ -96,6 +146,17 @@ func (m *Manager) RehashVersions(
 	ctx context.Context,
 	versions []string,
 ) error {
+	unlock, err := m.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	err = m.syncActivation(ctx)
+	if err != nil {
+		return err
+	}
+
 	var vers []*Version
 	for _, s := range versions {
 		v, err := m.Get(ctx, s)
@@ -109,193 +170,176 @@ func (m *Manager) RehashVersions(
 	return m.rehashVersions(ctx, false, vers)
 }
 
-func (m *Manager) rehashVersions(
+func (m *Manager) Exec(
 	ctx context.Context,
-	tidy bool,
-	versions []*Version,
+	program string,
+	args []string,
 ) error {
-	if log.Debug().Enabled() {
-		var vers []string
-		for _, v := range versions {
-			vers = append(vers, v.Version)
-		}
-		log.Debug().Strs("versions", vers).Msg("reshashing versions")
-	}
-
-	programs := map[string]bool{}
-	for _, ver := range versions {
-		for _, bin := range ver.Binaries {
-			base := filepath.Base(bin)
-			programs[base] = true
-		}
-	}
-
-	log.Debug().
-		Str("path", m.Config.Paths.Shims).
-		Msg("ensure shims directory exists")
-	err := os.MkdirAll(m.Config.Paths.Shims, 0o755)
+	res, err := m.Which(ctx, program)
 	if err != nil {
 		return err
 	}
 
-	shims, err := m.ListShims(ctx)
+	env := m.versionEnv(res.Version, res.BinDirs, false)
+
+	return m.execResolution(ctx, res, env, args)
+}
+
+func (m *Manager) ExecVersion(
+	ctx context.Context,
+	version string,
+	program string,
+	args []string,
+) error {
+	res, err := m.resolveBin(ctx, []string{version}, program)
 	if err != nil {
 		return err
 	}
 
-	shimMap := map[string]bool{}
-	for _, s := range shims {
-		base := filepath.Base(s)
-		shimMap[base] = true
-	}
+	env := m.versionEnv(version, res.BinDirs, true)
 
-	shim, err := m.shim()
-	if err != nil {
-		return err
+	return m.execResolution(ctx, res, env, args)
+}
+
+func (m *Manager) execResolution(
+	ctx context.Context,
+	res *Resolution,
+	env []*EnvVar,
+	args []string,
+) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
 	}
 
-	for name := range programs {
-		shimFile := filepath.Join(m.Config.Paths.Shims, name)
+	execArgs := append([]string{res.Bin}, args...)
 
-		log.Debug().Str("path", shimFile).Msg("writing shim")
-		err = os.WriteFile(shimFile, shim, 0o755)
-		if err != nil {
-			return err
-		}
+	log.Debug().
+		Str("bin", res.Bin).
+		Str("version", res.Version).
+		Strs("extra_path", res.BinDirs).
+		Strs("args", args).
+		Msg("executing")
 
-		var f fs.FileInfo
-		f, err = os.Stat(shimFile)
-		if err != nil {
-			return err
-		}
+	return syscall.Exec(res.Bin, execArgs, environ(env))
+}
 
-		if f.Mode().Perm() != 0o755 {
-			err = os.Chmod(shimFile, 0o755)
+type Resolution struct {
+	Program  string   `yaml:"program" json:"program"`
+	Bin      string   `yaml:"bin" json:"bin"`
+	Version  string   `yaml:"version" json:"version"`
+	Versions []string `yaml:"versions" json:"versions"`
+	SetBy    string   `yaml:"set_by,omitempty" json:"set_by,omitempty"`
+	BinDirs  []string `yaml:"bin_dirs,omitempty" json:"bin_dirs,omitempty"`
+}
+
+func (m *Manager) Which(
+	ctx context.Context,
+	program string,
+) (*Resolution, error) {
+	if name, spec, ok := m.Config.parseQualified(program); ok {
+		version, err := m.resolvePartialVersion(ctx, spec)
+		if err == nil {
+			res, err := m.resolveBin(ctx, []string{version}, name)
 			if err != nil {
-				return err
+				return nil, err
 			}
-		}
 
-		delete(shimMap, name)
-	}
+			res.SetBy = "qualified program name " + program
 
-	if tidy && len(shimMap) > 0 {
-		log.Debug().Msg("tidying shims")
-		for name := range shimMap {
-			shimFile := filepath.Join(m.Config.Paths.Shims, name)
-			log.Debug().Str("path", shimFile).Msg("removing shim")
-			err := os.Remove(shimFile)
-			if err != nil {
-				return err
-			}
+			return res, nil
 		}
 	}
 
-	return nil
-}
-
-var shimTemplate = template.Must(template.New("other").Parse(
-	`#!/usr/bin/env bash
-set -e
-[ -n "$EVM_DEBUG" ] && set -x
-
-program="${0##*/}"
-export EVM_ROOT="{{.Paths.Root}}"
-exec "{{.Paths.Binary}}" exec "$program" "$@"
-`))
+	current := m.CurrentFor(program)
+	if len(current.Versions) == 0 {
+		return nil, ErrNoCurrentVersion
+	}
 
-func (m *Manager) shim() ([]byte, error) {
-	var buf bytes.Buffer
-	err := shimTemplate.Execute(&buf, m.Config)
+	res, err := m.resolveBin(ctx, current.Versions, program)
 	if err != nil {
 		return nil, err
 	}
 
-	return buf.Bytes(), nil
+	res.SetBy = current.SetBy
+
+	return res, nil
 }
 
-func (m *Manager) ListShims(ctx context.Context) ([]string, error) {
-	log.Debug().Str("path", m.Config.Paths.Shims).Msg("reading shims")
+// Find program in the first of the given versions which provides it.
+// Versions which are not installed are skipped when more than one version is
+// given, so fallback versions do not need to all be installed.
+func (m *Manager) resolveBin(
+	ctx context.Context,
+	versions []string,
+	program string,
+) (*Resolution, error) {
+	res := &Resolution{Program: program, Versions: versions}
 
-	entries, err := os.ReadDir(m.Config.Paths.Shims)
-	if err != nil {
-		if errors.Is(err, fs.ErrNotExist) {
-			return []string{}, nil
+	var notFoundErr error
+	for _, version := range versions {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
 		}
-		return nil, err
-	}
 
-	r := []string{}
+		if version == SystemVersion {
+			if res.Bin == "" {
+				bin, err := m.FindSystemBin(program)
+				if err == nil {
+					res.Bin = bin
+					res.Version = version
+				} else if len(versions) == 1 {
+					return nil, err
+				}
+			}
 
-	for _, entry := range entries {
-		if ctx.Err() != nil {
-			return nil, ctx.Err()
+			continue
 		}
 
-		shimPath := filepath.Join(m.Config.Paths.Shims, entry.Name())
-		f, err := os.Stat(shimPath)
+		ver, err := m.Get(ctx, version)
 		if err != nil {
+			if len(versions) > 1 && errors.Is(err, ErrVersionNotFound) {
+				log.Debug().Str("version", version).
+					Msg("skipping version which is not installed")
+				if notFoundErr == nil {
+					notFoundErr = err
+				}
+
+				continue
+			}
+
 			return nil, err
 		}
 
-		if f.Mode().IsRegular() {
-			r = append(r, shimPath)
+		res.BinDirs = append(res.BinDirs, ver.BinDir)
+		if res.Bin != "" {
+			continue
 		}
-	}
-
-	return r, nil
-}
 
-func (m *Manager) Exec(
-	ctx context.Context,
-	program string,
-	args []string,
-) error {
-	current := m.CurrentVersion()
-	if current == "" {
-		return ErrNoCurrentVersion
-	}
-
-	return m.ExecVersion(ctx, current, program, args)
-}
-
-func (m *Manager) ExecVersion(
-	ctx context.Context,
-	version string,
-	program string,
-	args []string,
-) error {
-	ver, err := m.Get(ctx, version)
-	if err != nil {
-		return err
-	}
+		bin, err := ver.FindBin(program)
+		if err != nil {
+			if len(versions) == 1 {
+				return nil, err
+			}
 
-	bin, err := ver.FindBin(program)
-	if err != nil {
-		return err
-	}
+			continue
+		}
 
-	if ctx.Err() != nil {
-		return ctx.Err()
+		res.Bin = bin
+		res.Version = ver.Version
 	}
 
-	execArgs := append([]string{bin}, args...)
-	execEnv := os.Environ()
-
-	// Prepend selected version's bin directory to PATH.
-	for i := 0; i < len(execEnv); i++ {
-		if strings.HasPrefix(execEnv[i], "PATH=") {
-			execEnv[i] = "PATH=" + ver.BinDir + ":" + execEnv[i][5:]
+	if res.Bin == "" {
+		if len(res.BinDirs) == 0 && notFoundErr != nil {
+			return nil, notFoundErr
 		}
-	}
 
-	log.Debug().
-		Str("bin", bin).
-		Str("extra_path", ver.BinDir).
-		Strs("args", args).
-		Msg("executing")
+		return nil, fmt.Errorf(
+			`%wExecutable "%s" not found in Emacs versions %s`,
+			ErrBinNotFound, program, strings.Join(versions, ", "),
+		)
+	}
 
-	return syscall.Exec(bin, execArgs, execEnv)
+	return res, nil
 }
 
 func (m *Manager) FindBin(
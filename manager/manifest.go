@@ -0,0 +1,148 @@
+package manager
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+const shimManifestFileName = "shims.manifest"
+
+// shimManifest records the path of every shim evm has created, so that
+// files evm did not create are never overwritten or removed.
+type shimManifest struct {
+	path  string
+	shims map[string]bool
+}
+
+func loadShimManifest(root string) (*shimManifest, error) {
+	sm := &shimManifest{
+		path:  filepath.Join(root, shimManifestFileName),
+		shims: map[string]bool{},
+	}
+
+	b, err := os.ReadFile(sm.path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return sm, nil
+		}
+		return nil, err
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			sm.shims[line] = true
+		}
+	}
+
+	return sm, scanner.Err()
+}
+
+func (sm *shimManifest) Has(path string) bool {
+	return sm.shims[path]
+}
+
+func (sm *shimManifest) Add(path string) {
+	sm.shims[path] = true
+}
+
+func (sm *shimManifest) Remove(path string) {
+	delete(sm.shims, path)
+}
+
+// Prune removes entries for shims which no longer exist.
+func (sm *shimManifest) Prune() {
+	for path := range sm.shims {
+		_, err := os.Lstat(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			delete(sm.shims, path)
+		}
+	}
+}
+
+func (sm *shimManifest) Save() error {
+	paths := make([]string, 0, len(sm.shims))
+	for path := range sm.shims {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var buf bytes.Buffer
+	for _, path := range paths {
+		buf.WriteString(path + "\n")
+	}
+
+	log.Debug().Str("path", sm.path).Msg("updating shim manifest")
+
+	err := os.MkdirAll(filepath.Dir(sm.path), 0o755)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(sm.path, buf.Bytes(), 0o644)
+}
+
+// Reports if path is a shim written by evm. Shims created before evm kept a
+// manifest are recognized by their content or link target.
+func (m *Manager) ownsShim(manifest *shimManifest, path string) bool {
+	if manifest.Has(path) {
+		return true
+	}
+
+	lf, err := os.Lstat(path)
+	if err != nil {
+		return false
+	}
+
+	if lf.Mode()&fs.ModeSymlink != 0 {
+		target, err := os.Readlink(path)
+
+		return err == nil && target == m.Config.Paths.Binary
+	}
+
+	if !lf.Mode().IsRegular() {
+		return false
+	}
+
+	if bf, err := os.Stat(m.Config.Paths.Binary); err == nil &&
+		os.SameFile(lf, bf) {
+		return true
+	}
+
+	if lf.Size() > 4096 {
+		return false
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+
+	return bytes.HasPrefix(b, []byte("#!")) &&
+		bytes.Contains(b, []byte(`EVM_ROOT=`)) &&
+		bytes.Contains(b, []byte(` exec "$program" "$@"`))
+}
+
+// ShimRoot returns the root directory which owns the given shim, by checking
+// the manifest of the root assumed by the default shims directory layout.
+// Symlink and hardlink shims use this to find their root, as unlike script
+// shims they cannot carry EVM_ROOT themselves.
+func ShimRoot(shim string) (string, bool) {
+	shim = filepath.Clean(shim)
+	root := filepath.Dir(filepath.Dir(shim))
+
+	sm, err := loadShimManifest(root)
+	if err != nil || !sm.Has(shim) {
+		return "", false
+	}
+
+	return root, true
+}
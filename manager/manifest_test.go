@@ -0,0 +1,56 @@
+package manager
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestShimRoot(t *testing.T) {
+	root := t.TempDir()
+	shim := filepath.Join(root, "shims", "emacs")
+
+	sm, err := loadShimManifest(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sm.Add(shim)
+	err = sm.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		shim     string
+		wantRoot string
+		wantOK   bool
+	}{
+		{name: "listed shim", shim: shim, wantRoot: root, wantOK: true},
+		{
+			name:     "unclean path",
+			shim:     filepath.Join(root, "shims", ".", "emacs"),
+			wantRoot: root,
+			wantOK:   true,
+		},
+		{
+			name: "unlisted shim",
+			shim: filepath.Join(root, "shims", "emacsclient"),
+		},
+		{
+			name: "no manifest",
+			shim: filepath.Join(t.TempDir(), "shims", "emacs"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotRoot, gotOK := ShimRoot(tt.shim)
+
+			if gotRoot != tt.wantRoot || gotOK != tt.wantOK {
+				t.Errorf(
+					"ShimRoot() = %q, %v, want %q, %v",
+					gotRoot, gotOK, tt.wantRoot, tt.wantOK,
+				)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var constraintOps = []string{">=", "<=", "!=", ">", "<", "="}
+
+// MatchVersions returns installed versions satisfying all constraints in expr,
+// in ascending order. Constraints are separated by commas, for example
+// ">=27, <30". Versions are compared only to as many components as the
+// constraint gives, so "<=29" includes 29.4, and "29" or "=29" match all 29.x
+// releases.
+func (m *Manager) MatchVersions(
+	ctx context.Context,
+	expr string,
+) ([]*Version, error) {
+	var constraints []string
+	for _, c := range strings.Split(expr, ",") {
+		c = strings.TrimSpace(c)
+		if c != "" {
+			constraints = append(constraints, c)
+		}
+	}
+	if len(constraints) == 0 {
+		return nil, fmt.Errorf(
+			"%wversion constraint cannot be empty", ErrVersion,
+		)
+	}
+
+	for _, c := range constraints {
+		_, version := splitConstraint(c)
+		if version == "" {
+			return nil, fmt.Errorf(
+				`%wversion constraint "%s" is invalid`, ErrVersion, c,
+			)
+		}
+	}
+
+	versions, err := m.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	SortVersions(versions)
+
+	var r []*Version
+	for _, ver := range versions {
+		ok := true
+		for _, c := range constraints {
+			if !matchConstraint(c, ver.Version) {
+				ok = false
+				break
+			}
+		}
+
+		if ok {
+			r = append(r, ver)
+		}
+	}
+
+	return r, nil
+}
+
+// SortVersions sorts versions in ascending order.
+func SortVersions(versions []*Version) {
+	sort.SliceStable(versions, func(i, j int) bool {
+		return compareVersions(versions[i].Version, versions[j].Version) < 0
+	})
+}
+
+func splitConstraint(c string) (string, string) {
+	for _, op := range constraintOps {
+		if strings.HasPrefix(c, op) {
+			return op, strings.TrimSpace(c[len(op):])
+		}
+	}
+
+	return "", c
+}
+
+func matchConstraint(c string, version string) bool {
+	op, want := splitConstraint(c)
+
+	n := len(strings.Split(want, "."))
+	if parts := strings.Split(version, "."); len(parts) > n {
+		version = strings.Join(parts[:n], ".")
+	}
+	cmp := compareVersions(version, want)
+
+	switch op {
+	case ">=":
+		return cmp >= 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case "<":
+		return cmp < 0
+	case "!=":
+		return cmp != 0
+	default:
+		return cmp == 0
+	}
+}
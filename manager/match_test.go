@@ -0,0 +1,144 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a    string
+		b    string
+		want int
+	}{
+		{a: "29.4", b: "29.4", want: 0},
+		{a: "29.4", b: "29.3", want: 1},
+		{a: "29.3", b: "29.4", want: -1},
+		{a: "29.10", b: "29.9", want: 1},
+		{a: "9.1", b: "10.1", want: -1},
+		{a: "29.4", b: "29", want: 1},
+		{a: "29", b: "29.4", want: -1},
+		{a: "30.0.50", b: "30.0", want: 1},
+		{a: "29.4", b: "28.2", want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+" vs "+tt.b, func(t *testing.T) {
+			got := compareVersions(tt.a, tt.b)
+
+			if sign(got) != tt.want {
+				t.Errorf(
+					"compareVersions(%q, %q) = %d, want sign %d",
+					tt.a, tt.b, got, tt.want,
+				)
+			}
+		})
+	}
+}
+
+func sign(i int) int {
+	switch {
+	case i < 0:
+		return -1
+	case i > 0:
+		return 1
+	}
+
+	return 0
+}
+
+func TestMatchConstraint(t *testing.T) {
+	tests := []struct {
+		c       string
+		version string
+		want    bool
+	}{
+		{c: ">=28", version: "27.2", want: false},
+		{c: ">=28", version: "28.1", want: true},
+		{c: ">=28", version: "29.4", want: true},
+		{c: ">28", version: "28.2", want: false},
+		{c: ">28", version: "29.1", want: true},
+		{c: "<=29", version: "29.4", want: true},
+		{c: "<=29", version: "30.1", want: false},
+		{c: "<29", version: "28.2", want: true},
+		{c: "<29", version: "29.1", want: false},
+		{c: "=29", version: "29.4", want: true},
+		{c: "=29", version: "30.1", want: false},
+		{c: "=29.4", version: "29.4", want: true},
+		{c: "=29.4", version: "29.40", want: false},
+		{c: "29", version: "29.1", want: true},
+		{c: "29", version: "290.1", want: false},
+		{c: "!=29", version: "29.4", want: false},
+		{c: "!=29", version: "28.2", want: true},
+		{c: ">= 28.2", version: "28.2", want: true},
+		{c: "<29.2", version: "29.1.90", want: true},
+		{c: ">=30.0.50", version: "30.0", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.c+" "+tt.version, func(t *testing.T) {
+			got := matchConstraint(tt.c, tt.version)
+
+			if got != tt.want {
+				t.Errorf(
+					"matchConstraint(%q, %q) = %v, want %v",
+					tt.c, tt.version, got, tt.want,
+				)
+			}
+		})
+	}
+}
+
+func TestManagerMatchVersions(t *testing.T) {
+	dir := t.TempDir()
+	for _, v := range []string{"27.2", "28.2", "29.4", "30.1", "9.1"} {
+		err := os.MkdirAll(filepath.Join(dir, v, "bin"), 0o755)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	m := &Manager{Config: &Config{Paths: PathsConfig{Versions: dir}}}
+
+	tests := []struct {
+		expr    string
+		want    []string
+		wantErr error
+	}{
+		{expr: ">=28", want: []string{"28.2", "29.4", "30.1"}},
+		{expr: "<=29", want: []string{"9.1", "27.2", "28.2", "29.4"}},
+		{expr: "=29", want: []string{"29.4"}},
+		{expr: "29", want: []string{"29.4"}},
+		{expr: ">=27, <30", want: []string{"27.2", "28.2", "29.4"}},
+		{expr: ">=27,!=28", want: []string{"27.2", "29.4", "30.1"}},
+		{expr: ">=31", want: nil},
+		{expr: "", wantErr: ErrVersion},
+		{expr: " , ", wantErr: ErrVersion},
+		{expr: ">=", wantErr: ErrVersion},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			vers, err := m.MatchVersions(context.Background(), tt.expr)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("MatchVersions() error = %v, want %v",
+						err, tt.wantErr)
+				}
+
+				return
+			}
+			if err != nil {
+				t.Fatalf("MatchVersions() error = %v", err)
+			}
+
+			var got []string
+			for _, ver := range vers {
+				got = append(got, ver.Version)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MatchVersions() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package manager
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const programEnvPrefix = "EVM_VERSION_"
+
+// Returns the environment variable used to pin the version of program, for
+// example "EVM_VERSION_EMACSCLIENT" for "emacsclient".
+func programEnvName(program string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+
+		return '_'
+	}, program)
+
+	return programEnvPrefix + name
+}
+
+// ProgramCurrent returns the version pinned for program by its
+// EVM_VERSION_<PROGRAM> environment variable or the "programs" config, in that
+// order, or nil if it is not pinned. Pins take precedence over EVM_VERSION and
+// version files, except when running under an explicit version with "evm with"
+// and similar commands, which override every pin.
+func (conf *Config) ProgramCurrent(program string) *CurrentConfig {
+	name := programEnvName(program)
+	if v := parseVersionList(os.Getenv(name)); len(v) > 0 {
+		cc := &CurrentConfig{}
+		cc.set(v, name+" environment variable")
+
+		return cc
+	}
+
+	if cc, ok := conf.Programs[program]; ok {
+		return cc
+	}
+
+	return nil
+}
+
+// Returns the names of all environment variables which currently pin a
+// program's version, from the environment and the "programs" config.
+func (conf *Config) programEnvNames() []string {
+	var r []string
+	for _, e := range os.Environ() {
+		name, _, _ := strings.Cut(e, "=")
+		if strings.HasPrefix(name, programEnvPrefix) {
+			r = append(r, name)
+		}
+	}
+	for program := range conf.Programs {
+		if name := programEnvName(program); !stringsContain(r, name) {
+			r = append(r, name)
+		}
+	}
+	sort.Strings(r)
+
+	return r
+}
+
+func (m *Manager) CurrentFor(program string) *CurrentConfig {
+	if cc := m.Config.ProgramCurrent(program); cc != nil {
+		return cc
+	}
+
+	return &m.Config.Current
+}
+
+func (m *Manager) PinnedPrograms(
+	ctx context.Context,
+) (map[string]*CurrentConfig, error) {
+	versions, err := m.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	names := map[string]bool{}
+	for name := range m.Config.Programs {
+		names[name] = true
+	}
+	for _, ver := range versions {
+		for _, bin := range ver.Binaries {
+			names[filepath.Base(bin)] = true
+		}
+	}
+
+	r := map[string]*CurrentConfig{}
+	for name := range names {
+		if cc := m.Config.ProgramCurrent(name); cc != nil {
+			r[name] = cc
+		}
+	}
+
+	return r, nil
+}
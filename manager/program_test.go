@@ -0,0 +1,54 @@
+package manager
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestVersionEnvOverridesPins(t *testing.T) {
+	t.Setenv("EVM_VERSION_EMACS", "28.2")
+
+	conf := &Config{Programs: map[string]*CurrentConfig{}}
+	conf.Programs["emacsclient"] = &CurrentConfig{}
+	conf.Programs["emacsclient"].set([]string{"28.2"}, "config.yaml")
+	mgr := &Manager{Config: conf}
+
+	env := mgr.versionEnv("29.4", nil, true)
+
+	var got []string
+	for _, e := range env {
+		got = append(got, e.Name+"="+e.Value)
+	}
+	want := []string{
+		"EVM_VERSION=29.4",
+		"EVM_VERSION_EMACS=29.4",
+		"EVM_VERSION_EMACSCLIENT=29.4",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("versionEnv() = %#v, want %#v", got, want)
+	}
+
+	for _, e := range env {
+		t.Setenv(e.Name, e.Value)
+	}
+	for _, program := range []string{"emacs", "emacsclient"} {
+		cc := mgr.CurrentFor(program)
+		if cc.Version != "29.4" {
+			t.Errorf(
+				"CurrentFor(%q).Version = %q, want %q",
+				program, cc.Version, "29.4",
+			)
+		}
+	}
+}
+
+func TestVersionEnvWithoutPin(t *testing.T) {
+	t.Setenv("EVM_VERSION_EMACS", "28.2")
+
+	mgr := &Manager{Config: &Config{}}
+
+	env := mgr.versionEnv("29.4", nil, false)
+	if len(env) != 0 {
+		t.Errorf("versionEnv() = %#v, want none", env)
+	}
+}
@@ -0,0 +1,175 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Placeholders supported in qualified shim name patterns, for example
+// "{program}-{version}" or "{program}@{major}".
+const (
+	qualifiedProgram = "{program}"
+	qualifiedVersion = "{version}"
+	qualifiedMajor   = "{major}"
+	qualifiedMinor   = "{minor}"
+)
+
+func validateQualifiedPatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if !strings.Contains(pattern, qualifiedProgram) ||
+			(!strings.Contains(pattern, qualifiedVersion) &&
+				!strings.Contains(pattern, qualifiedMajor)) {
+			return fmt.Errorf(
+				`%wshims.qualified pattern "%s" must contain "%s", `+
+					`and "%s" or "%s"`,
+				ErrConfig, pattern,
+				qualifiedProgram, qualifiedVersion, qualifiedMajor,
+			)
+		}
+	}
+
+	return nil
+}
+
+// Returns the qualified shim name for program in version, or an empty
+// string if version has no major or minor component required by pattern.
+func qualifiedName(pattern, program, version string) string {
+	parts := strings.SplitN(version, ".", 3)
+
+	if strings.Contains(pattern, qualifiedMajor) &&
+		!isNumeric(parts[0]) {
+		return ""
+	}
+	if strings.Contains(pattern, qualifiedMinor) &&
+		(len(parts) < 2 || !isNumeric(parts[1])) {
+		return ""
+	}
+
+	r := strings.ReplaceAll(pattern, qualifiedProgram, program)
+	r = strings.ReplaceAll(r, qualifiedVersion, version)
+	r = strings.ReplaceAll(r, qualifiedMajor, parts[0])
+	if len(parts) > 1 {
+		r = strings.ReplaceAll(r, qualifiedMinor, parts[1])
+	}
+
+	return r
+}
+
+var qualifiedPlaceholders = strings.NewReplacer(
+	regexp.QuoteMeta(qualifiedProgram), `(?P<program>.+)`,
+	regexp.QuoteMeta(qualifiedVersion), `(?P<version>.+)`,
+	regexp.QuoteMeta(qualifiedMajor), `(?P<major>[0-9]+)`,
+	regexp.QuoteMeta(qualifiedMinor), `(?P<minor>[0-9]+)`,
+)
+
+// Parse a qualified shim name like "emacs-29.4" or "emacs@29" into its
+// program name and version, which may be partial. Returns false if name does
+// not match any of the configured patterns.
+func (conf *Config) parseQualified(name string) (string, string, bool) {
+	for _, pattern := range conf.Shims.Qualified {
+		re, err := regexp.Compile(
+			"^" + qualifiedPlaceholders.Replace(regexp.QuoteMeta(pattern)) + "$",
+		)
+		if err != nil {
+			continue
+		}
+
+		match := re.FindStringSubmatch(name)
+		if match == nil {
+			continue
+		}
+
+		var program, version, major, minor string
+		for i, group := range re.SubexpNames() {
+			switch group {
+			case "program":
+				program = match[i]
+			case "version":
+				version = match[i]
+			case "major":
+				major = match[i]
+			case "minor":
+				minor = match[i]
+			}
+		}
+
+		if version == "" {
+			version = major
+			if minor != "" {
+				version += "." + minor
+			}
+		}
+
+		return program, version, true
+	}
+
+	return "", "", false
+}
+
+// Resolve a full or partial version like "29" or "29.4" to the newest
+// installed version it matches.
+func (m *Manager) resolvePartialVersion(
+	ctx context.Context,
+	spec string,
+) (string, error) {
+	versions, err := m.List(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	var matches []string
+	for _, ver := range versions {
+		if ver.Version == spec {
+			return spec, nil
+		}
+		if strings.HasPrefix(ver.Version, spec+".") {
+			matches = append(matches, ver.Version)
+		}
+	}
+
+	if len(matches) == 0 {
+		return "", fmt.Errorf(
+			"%wNo installed version matches %s in %s",
+			ErrVersionNotFound, spec, m.Config.Paths.Versions,
+		)
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		return compareVersions(matches[i], matches[j]) > 0
+	})
+
+	return matches[0], nil
+}
+
+// Compare two dot separated versions, numerically where possible.
+func compareVersions(a, b string) int {
+	as := strings.Split(a, ".")
+	bs := strings.Split(b, ".")
+
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aerr := strconv.Atoi(as[i])
+		bn, berr := strconv.Atoi(bs[i])
+
+		switch {
+		case aerr == nil && berr == nil && an != bn:
+			if an < bn {
+				return -1
+			}
+			return 1
+		case (aerr != nil || berr != nil) && as[i] != bs[i]:
+			return strings.Compare(as[i], bs[i])
+		}
+	}
+
+	return len(as) - len(bs)
+}
+
+func isNumeric(s string) bool {
+	_, err := strconv.Atoi(s)
+
+	return err == nil
+}
@@ -0,0 +1,97 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+)
+
+type ExcludedBinary struct {
+	Name string `yaml:"name" json:"name"`
+	Rule string `yaml:"rule" json:"rule"`
+}
+
+type ShimExclusion struct {
+	Program string `yaml:"program" json:"program"`
+	Version string `yaml:"version" json:"version"`
+	Rule    string `yaml:"rule" json:"rule"`
+}
+
+func validatePatterns(name string, patterns []string) error {
+	for _, pattern := range patterns {
+		_, err := filepath.Match(pattern, "")
+		if err != nil {
+			return fmt.Errorf(
+				`%w%s pattern "%s" is invalid: %s`,
+				ErrConfig, name, pattern, err,
+			)
+		}
+	}
+
+	return nil
+}
+
+func matchPattern(patterns []string, name string) (string, bool) {
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return pattern, true
+		}
+	}
+
+	return "", false
+}
+
+// Returns the rule which excludes program in version from being shimmed and
+// executed, or an empty string if it is not excluded. Per-version rules take
+// precedence over global rules, and exclude rules over include rules.
+func (conf *Config) excludedBy(version, program string) string {
+	prefix := "versions." + version
+
+	var vc VersionConfig
+	if v, ok := conf.Versions[version]; ok {
+		vc = *v
+	}
+
+	if p, ok := matchPattern(vc.Exclude, program); ok {
+		return prefix + ".exclude " + strconv.Quote(p)
+	}
+	if _, ok := matchPattern(vc.Include, program); ok {
+		return ""
+	}
+	if p, ok := matchPattern(conf.Shims.Exclude, program); ok {
+		return "shims.exclude " + strconv.Quote(p)
+	}
+	if len(vc.Include) > 0 {
+		return prefix + ".include"
+	}
+	if len(conf.Shims.Include) > 0 {
+		if _, ok := matchPattern(conf.Shims.Include, program); !ok {
+			return "shims.include"
+		}
+	}
+
+	return ""
+}
+
+func (m *Manager) ShimExclusions(
+	ctx context.Context,
+) ([]*ShimExclusion, error) {
+	versions, err := m.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r := []*ShimExclusion{}
+	for _, ver := range versions {
+		for _, ex := range ver.Excluded {
+			r = append(r, &ShimExclusion{
+				Program: ex.Name,
+				Version: ver.Version,
+				Rule:    ex.Rule,
+			})
+		}
+	}
+
+	return r, nil
+}
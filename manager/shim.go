@@ -0,0 +1,342 @@
+package manager
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"text/template"
+
+	"github.com/rs/zerolog/log"
+)
+
+func (m *Manager) rehashVersions(
+	ctx context.Context,
+	tidy bool,
+	versions []*Version,
+) error {
+	if !m.Config.Activation.Shims() {
+		log.Debug().
+			Str("activation", string(m.Config.Activation)).
+			Msg("shims are disabled, skipping rehash")
+
+		return nil
+	}
+
+	if log.Debug().Enabled() {
+		var vers []string
+		for _, v := range versions {
+			vers = append(vers, v.Version)
+		}
+		log.Debug().Strs("versions", vers).Msg("reshashing versions")
+	}
+
+	// Symlink and hardlink shims find their root from the shims directory's
+	// location, which only works for the default layout.
+	if m.Config.Shims.Mode != ScriptShims &&
+		filepath.Dir(m.Config.Paths.Shims) != m.Config.Paths.Root {
+		log.Warn().
+			Str("mode", string(m.Config.Shims.Mode)).
+			Msgf(
+				"shims outside of %s only work with EVM_ROOT set, "+
+					"consider shims mode \"%s\"",
+				m.Config.Paths.Root, ScriptShims,
+			)
+	}
+
+	programs := m.Config.programVersions(versions)
+
+	log.Debug().
+		Str("path", m.Config.Paths.Shims).
+		Msg("ensure shims directory exists")
+	err := os.MkdirAll(m.Config.Paths.Shims, 0o755)
+	if err != nil {
+		return err
+	}
+
+	manifest, err := loadShimManifest(m.Config.Paths.Root)
+	if err != nil {
+		return err
+	}
+
+	shims, err := m.listShims(ctx, manifest)
+	if err != nil {
+		return err
+	}
+
+	shimMap := map[string]bool{}
+	for _, s := range shims {
+		base := filepath.Base(s)
+		shimMap[base] = true
+	}
+
+	tmpl, err := m.shimTemplate()
+	if err != nil {
+		return err
+	}
+
+	for name, providers := range programs {
+		shimFile := filepath.Join(m.Config.Paths.Shims, name)
+		if !shimMap[name] {
+			if _, err = os.Lstat(shimFile); err == nil {
+				log.Warn().Str("path", shimFile).
+					Msg("skipping shim, file exists and was not created by evm")
+
+				continue
+			}
+		}
+
+		var script []byte
+		script, err = renderShim(tmpl, &ShimData{
+			Config:  m.Config,
+			Program: name,
+			Version: providers[0],
+		})
+		if err != nil {
+			return err
+		}
+
+		err = m.writeShim(name, script)
+		if err != nil {
+			return err
+		}
+
+		manifest.Add(shimFile)
+		delete(shimMap, name)
+	}
+
+	if tidy {
+		log.Debug().Msg("tidying shims")
+		for name := range shimMap {
+			shimFile := filepath.Join(m.Config.Paths.Shims, name)
+			log.Debug().Str("path", shimFile).Msg("removing shim")
+			err := os.Remove(shimFile)
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+
+			manifest.Remove(shimFile)
+		}
+
+		manifest.Prune()
+	}
+
+	return manifest.Save()
+}
+
+func (m *Manager) writeShim(name string, script []byte) error {
+	shimFile := filepath.Join(m.Config.Paths.Shims, name)
+
+	// Shims are always replaced by renaming a new file into place, which
+	// also avoids writing through a symlink or hardlink shim into the evm
+	// binary itself.
+	switch m.Config.Shims.Mode {
+	case SymlinkShims:
+		log.Debug().Str("path", shimFile).Msg("symlinking shim")
+		return linkAtomic(os.Symlink, m.Config.Paths.Binary, shimFile)
+	case HardlinkShims:
+		log.Debug().Str("path", shimFile).Msg("hardlinking shim")
+		err := linkAtomic(os.Link, m.Config.Paths.Binary, shimFile)
+		if errors.Is(err, syscall.EXDEV) {
+			return fmt.Errorf(
+				`%wshims mode "%s" requires %s and %s to be on the same `+
+					`filesystem, use shims mode "%s" instead`,
+				ErrConfig, HardlinkShims, m.Config.Paths.Binary,
+				m.Config.Paths.Shims, SymlinkShims,
+			)
+		}
+
+		return err
+	}
+
+	log.Debug().Str("path", shimFile).Msg("writing shim")
+
+	return writeFileAtomic(shimFile, script, 0o755)
+}
+
+// Returns the names of all programs provided by versions, including any
+// qualified shim names, along with the versions which provide each program
+// in the given order. Programs already qualified with their version, like the
+// "emacs-29.4" binary shipped with Emacs, are not qualified again.
+func (conf *Config) programVersions(versions []*Version) map[string][]string {
+	r := map[string][]string{}
+	add := func(name, version string) {
+		if !stringsContain(r[name], version) {
+			r[name] = append(r[name], version)
+		}
+	}
+
+	for _, ver := range versions {
+		for _, bin := range ver.Binaries {
+			base := filepath.Base(bin)
+			add(base, ver.Version)
+
+			if strings.HasSuffix(base, "-"+ver.Version) {
+				continue
+			}
+
+			for _, pattern := range conf.Shims.Qualified {
+				name := qualifiedName(pattern, base, ver.Version)
+				if name != "" && name != base {
+					add(name, ver.Version)
+				}
+			}
+		}
+	}
+
+	return r
+}
+
+type Shim struct {
+	Name     string   `yaml:"name" json:"name"`
+	Path     string   `yaml:"path" json:"path"`
+	Versions []string `yaml:"versions" json:"versions"`
+	Current  bool     `yaml:"current" json:"current"`
+	Orphaned bool     `yaml:"orphaned" json:"orphaned"`
+}
+
+func (m *Manager) Shims(ctx context.Context) ([]*Shim, error) {
+	versions, err := m.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	programs := m.Config.programVersions(versions)
+
+	paths, err := m.ListShims(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r := []*Shim{}
+	for _, path := range paths {
+		name := filepath.Base(path)
+		shim := &Shim{
+			Name:     name,
+			Path:     path,
+			Versions: programs[name],
+			Orphaned: len(programs[name]) == 0,
+		}
+		if shim.Versions == nil {
+			shim.Versions = []string{}
+		}
+
+		for _, v := range m.CurrentFor(name).Versions {
+			if stringsContain(shim.Versions, v) {
+				shim.Current = true
+				break
+			}
+		}
+
+		r = append(r, shim)
+	}
+
+	return r, nil
+}
+
+// ShimData is passed to shim templates when rendering script shims.
+type ShimData struct {
+	// Config is the full evm configuration, for example
+	// {{ .Config.Paths.Root }} or {{ .Config.Paths.Binary }}.
+	Config *Config
+
+	// Program is the name of the program the shim executes.
+	Program string
+
+	// Version is the Emacs version which provided Program when the shim was
+	// written. The version to execute is resolved each time the shim runs,
+	// so this is informational only.
+	Version string
+}
+
+var shimTemplateFuncs = template.FuncMap{
+	"shellquote": shellQuote,
+}
+
+var defaultShimTemplate = template.Must(
+	template.New("shim").Funcs(shimTemplateFuncs).Parse(
+		`#!/usr/bin/env bash
+set -e
+[ -n "$EVM_DEBUG" ] && set -x
+
+program="${0##*/}"
+export EVM_ROOT={{ shellquote .Config.Paths.Root }}
+exec {{ shellquote .Config.Paths.Binary }} exec "$program" "$@"
+`))
+
+func (m *Manager) shimTemplate() (*template.Template, error) {
+	path := m.Config.Shims.Template
+	if path == "" {
+		return defaultShimTemplate, nil
+	}
+
+	log.Debug().Str("path", path).Msg("reading shim template")
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return template.New(filepath.Base(path)).
+		Funcs(shimTemplateFuncs).
+		Option("missingkey=error").
+		Parse(string(b))
+}
+
+func renderShim(tmpl *template.Template, data *ShimData) ([]byte, error) {
+	var buf bytes.Buffer
+	err := tmpl.Execute(&buf, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Quote s for use as a single word in POSIX shell scripts.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func (m *Manager) ListShims(ctx context.Context) ([]string, error) {
+	manifest, err := loadShimManifest(m.Config.Paths.Root)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.listShims(ctx, manifest)
+}
+
+func (m *Manager) listShims(
+	ctx context.Context,
+	manifest *shimManifest,
+) ([]string, error) {
+	log.Debug().Str("path", m.Config.Paths.Shims).Msg("reading shims")
+
+	entries, err := os.ReadDir(m.Config.Paths.Shims)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return []string{}, nil
+		}
+		return nil, err
+	}
+
+	r := []string{}
+
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		shimPath := filepath.Join(m.Config.Paths.Shims, entry.Name())
+		if m.ownsShim(manifest, shimPath) {
+			r = append(r, shimPath)
+		}
+	}
+
+	return r, nil
+}
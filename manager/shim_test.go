@@ -0,0 +1,128 @@
+package manager
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestConfigProgramVersions(t *testing.T) {
+	versions := []*Version{
+		{
+			Version: "30.1",
+			Binaries: []string{
+				"/evm/versions/30.1/bin/emacs",
+				"/evm/versions/30.1/bin/emacs-30.1",
+			},
+		},
+		{
+			Version: "29.4",
+			Binaries: []string{
+				"/evm/versions/29.4/bin/emacs",
+				"/evm/versions/29.4/bin/emacs-29.4",
+				"/evm/versions/29.4/bin/etags",
+			},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		qualified []string
+		want      map[string][]string
+	}{
+		{
+			name: "no qualified names",
+			want: map[string][]string{
+				"emacs":      {"30.1", "29.4"},
+				"emacs-30.1": {"30.1"},
+				"emacs-29.4": {"29.4"},
+				"etags":      {"29.4"},
+			},
+		},
+		{
+			name:      "version and major qualified names",
+			qualified: []string{"{program}-{version}", "{program}@{major}"},
+			want: map[string][]string{
+				"emacs":      {"30.1", "29.4"},
+				"emacs-30.1": {"30.1"},
+				"emacs-29.4": {"29.4"},
+				"emacs@30":   {"30.1"},
+				"emacs@29":   {"29.4"},
+				"etags":      {"29.4"},
+				"etags-29.4": {"29.4"},
+				"etags@29":   {"29.4"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := &Config{Shims: ShimsConfig{Qualified: tt.qualified}}
+
+			got := conf.programVersions(versions)
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("programVersions() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRehashHardlinkShimsTwice(t *testing.T) {
+	root := t.TempDir()
+	binDir := filepath.Join(root, "versions", "29.4", "bin")
+	err := os.MkdirAll(binDir, 0o755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{
+		filepath.Join(binDir, "emacs"),
+		filepath.Join(root, "evm"),
+	} {
+		err = os.WriteFile(path, []byte("#!/bin/sh\n"), 0o755)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mgr, err := New(&Config{
+		Activation: ShimsActivation,
+		Shims:      ShimsConfig{Mode: HardlinkShims},
+		Paths: PathsConfig{
+			Binary:   filepath.Join(root, "evm"),
+			Root:     root,
+			Shims:    filepath.Join(root, "shims"),
+			Versions: filepath.Join(root, "versions"),
+			Active:   filepath.Join(root, "active"),
+			Share:    filepath.Join(root, "share"),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		err = mgr.RehashAll(ctx)
+		if err != nil {
+			t.Fatalf("RehashAll() error = %v", err)
+		}
+	}
+
+	entries, err := os.ReadDir(mgr.Config.Paths.Shims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			t.Errorf("temporary file %s left in shims", entry.Name())
+		}
+		names = append(names, entry.Name())
+	}
+	if want := []string{"emacs"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("shims = %#v, want %#v", names, want)
+	}
+}
@@ -0,0 +1,58 @@
+package manager
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SystemVersion is a pseudo-version which resolves programs from PATH,
+// ignoring evm's shims.
+const SystemVersion = "system"
+
+// Returns PATH without directories managed by evm: the shims directory, the
+// active version's bin directory, and any directory within a version.
+func (m *Manager) systemPath() []string {
+	shims := filepath.Clean(m.Config.Paths.Shims)
+	active := filepath.Join(m.Config.Paths.Active, "bin")
+	versions := filepath.Clean(m.Config.Paths.Versions) +
+		string(os.PathSeparator)
+
+	var r []string
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir == "" {
+			continue
+		}
+
+		clean := filepath.Clean(dir)
+		if clean == shims || clean == active ||
+			strings.HasPrefix(clean, versions) {
+			continue
+		}
+
+		r = append(r, dir)
+	}
+
+	return r
+}
+
+func (m *Manager) FindSystemBin(name string) (string, error) {
+	for _, dir := range m.systemPath() {
+		path := filepath.Join(dir, name)
+
+		f, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+
+		if f.Mode().IsRegular() && f.Mode().Perm()&0111 == 0111 {
+			return path, nil
+		}
+	}
+
+	return "", fmt.Errorf(
+		`%wExecutable "%s" not found in PATH outside of evm`,
+		ErrBinNotFound, name,
+	)
+}
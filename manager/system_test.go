@@ -0,0 +1,63 @@
+package manager
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSystemPath(t *testing.T) {
+	m := &Manager{Config: &Config{Paths: PathsConfig{
+		Root:     "/evm",
+		Shims:    "/evm/shims",
+		Active:   "/evm/active",
+		Versions: "/evm/versions",
+	}}}
+
+	tests := []struct {
+		name string
+		path string
+		want []string
+	}{
+		{
+			name: "no evm directories",
+			path: "/usr/local/bin:/usr/bin",
+			want: []string{"/usr/local/bin", "/usr/bin"},
+		},
+		{
+			name: "shims",
+			path: "/evm/shims:/usr/bin:/evm/shims/",
+			want: []string{"/usr/bin"},
+		},
+		{
+			name: "active bin",
+			path: "/evm/active/bin:/usr/bin",
+			want: []string{"/usr/bin"},
+		},
+		{
+			name: "version bin dirs",
+			path: "/evm/versions/29.4/bin:/usr/bin:/evm/versions/28.2/bin",
+			want: []string{"/usr/bin"},
+		},
+		{
+			name: "similarly named directories",
+			path: "/evm/versions-old/bin:/evm/active/lib:/usr/bin",
+			want: []string{"/evm/versions-old/bin", "/evm/active/lib", "/usr/bin"},
+		},
+		{
+			name: "empty entries",
+			path: ":/usr/bin::",
+			want: []string{"/usr/bin"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("PATH", tt.path)
+
+			got := m.systemPath()
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("systemPath() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package manager
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+var ErrNoVersionFile = fmt.Errorf("%w", Err)
+
+const trustFileName = "trusted"
+
+// trustStore is an allow-list of project-local version files, stored under
+// $EVM_ROOT in sha256sum format, one "<content hash>  <path>" line per file.
+type trustStore struct {
+	path    string
+	entries map[string]string
+}
+
+func loadTrustStore(root string) (*trustStore, error) {
+	ts := &trustStore{
+		path:    filepath.Join(root, trustFileName),
+		entries: map[string]string{},
+	}
+
+	b, err := os.ReadFile(ts.path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return ts, nil
+		}
+		return nil, err
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		hash, path, ok := strings.Cut(scanner.Text(), "  ")
+		if ok && path != "" {
+			ts.entries[path] = hash
+		}
+	}
+
+	return ts, scanner.Err()
+}
+
+func (ts *trustStore) Trusted(path string, content []byte) bool {
+	hash, ok := ts.entries[trustPath(path)]
+
+	return ok && hash == contentHash(content)
+}
+
+func (ts *trustStore) Add(path string, content []byte) {
+	ts.entries[trustPath(path)] = contentHash(content)
+}
+
+func (ts *trustStore) Remove(path string) bool {
+	var removed bool
+	for _, p := range []string{trustPath(path), path} {
+		if _, ok := ts.entries[p]; ok {
+			delete(ts.entries, p)
+			removed = true
+		}
+	}
+
+	return removed
+}
+
+func (ts *trustStore) Save() error {
+	paths := make([]string, 0, len(ts.entries))
+	for path := range ts.entries {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var buf bytes.Buffer
+	for _, path := range paths {
+		buf.WriteString(ts.entries[path] + "  " + path + "\n")
+	}
+
+	log.Debug().Str("path", ts.path).Msg("updating trust file")
+
+	err := os.MkdirAll(filepath.Dir(ts.path), 0o755)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(ts.path, buf.Bytes(), 0o644)
+}
+
+// Returns path with symlinks in its directory resolved, so a project reached
+// through different paths has a single trust entry.
+func trustPath(path string) string {
+	dir, err := filepath.EvalSymlinks(filepath.Dir(path))
+	if err != nil {
+		return path
+	}
+
+	return filepath.Join(dir, filepath.Base(path))
+}
+
+func contentHash(b []byte) string {
+	sum := sha256.Sum256(b)
+
+	return hex.EncodeToString(sum[:])
+}
+
+func (m *Manager) Trust(ctx context.Context, dir string) ([]string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	ts, err := loadTrustStore(m.Config.Paths.Root)
+	if err != nil {
+		return nil, err
+	}
+
+	var trusted []string
+	for _, name := range projectVersionFileNames {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		path := filepath.Join(dir, name)
+		b, err := os.ReadFile(path)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+
+		log.Debug().Str("path", path).Msg("trusting version file")
+		ts.Add(path, b)
+		trusted = append(trusted, path)
+	}
+
+	if len(trusted) == 0 {
+		return nil, fmt.Errorf(
+			"%wNo version file found in %s", ErrNoVersionFile, dir,
+		)
+	}
+
+	err = ts.Save()
+	if err != nil {
+		return nil, err
+	}
+	m.Config.projectSources = nil
+
+	return trusted, nil
+}
+
+func (m *Manager) Untrust(ctx context.Context, dir string) ([]string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	ts, err := loadTrustStore(m.Config.Paths.Root)
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []string
+	for _, name := range projectVersionFileNames {
+		path := filepath.Join(dir, name)
+		if ts.Remove(path) {
+			log.Debug().Str("path", path).Msg("untrusting version file")
+			removed = append(removed, path)
+		}
+	}
+
+	if len(removed) == 0 {
+		return nil, nil
+	}
+
+	err = ts.Save()
+	if err != nil {
+		return nil, err
+	}
+	m.Config.projectSources = nil
+
+	return removed, nil
+}
@@ -0,0 +1,304 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func newTrustTestManager(t *testing.T) (*Manager, string) {
+	t.Helper()
+
+	root := t.TempDir()
+	project := t.TempDir()
+
+	mgr, err := New(&Config{
+		Paths:        PathsConfig{Root: root},
+		ToolVersions: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return mgr, project
+}
+
+func writeTestFile(t *testing.T, path string, content string) {
+	t.Helper()
+
+	err := os.WriteFile(path, []byte(content), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Returns the source for path, as found by projectVersionSources.
+func projectSource(
+	t *testing.T,
+	conf *Config,
+	dir string,
+	path string,
+) *CurrentSource {
+	t.Helper()
+
+	sources, err := conf.projectVersionSources(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, src := range sources {
+		if src.Source == path {
+			return src
+		}
+	}
+
+	t.Fatalf("no source found for %s", path)
+
+	return nil
+}
+
+func TestUntrustedVersionFileIgnored(t *testing.T) {
+	mgr, project := newTrustTestManager(t)
+	path := filepath.Join(project, versionFileName)
+	writeTestFile(t, path, "29.4\n")
+
+	src := projectSource(t, mgr.Config, project, path)
+
+	if !src.Exists {
+		t.Error("source does not exist")
+	}
+	if src.Ignored != ignoredUntrusted {
+		t.Errorf("Ignored = %q, want %q", src.Ignored, ignoredUntrusted)
+	}
+	if src.usable() {
+		t.Error("untrusted source is usable")
+	}
+}
+
+func TestTrustedVersionFileUsed(t *testing.T) {
+	mgr, project := newTrustTestManager(t)
+	path := filepath.Join(project, versionFileName)
+	writeTestFile(t, path, "29.4 28.2\n")
+
+	trusted, err := mgr.Trust(context.Background(), project)
+	if err != nil {
+		t.Fatalf("Trust() error = %v", err)
+	}
+	if want := []string{path}; !reflect.DeepEqual(trusted, want) {
+		t.Errorf("Trust() = %#v, want %#v", trusted, want)
+	}
+
+	src := projectSource(t, mgr.Config, project, path)
+
+	if src.Ignored != "" {
+		t.Errorf("Ignored = %q, want empty", src.Ignored)
+	}
+	if want := []string{"29.4", "28.2"}; !reflect.DeepEqual(
+		src.Versions, want,
+	) {
+		t.Errorf("Versions = %#v, want %#v", src.Versions, want)
+	}
+	if !src.usable() {
+		t.Error("trusted source is not usable")
+	}
+}
+
+func TestTrustedVersionFileChanged(t *testing.T) {
+	mgr, project := newTrustTestManager(t)
+	path := filepath.Join(project, versionFileName)
+	writeTestFile(t, path, "29.4\n")
+
+	_, err := mgr.Trust(context.Background(), project)
+	if err != nil {
+		t.Fatalf("Trust() error = %v", err)
+	}
+
+	writeTestFile(t, path, "30.1\n")
+
+	src := projectSource(t, mgr.Config, project, path)
+
+	if src.Ignored != ignoredUntrusted {
+		t.Errorf("Ignored = %q, want %q", src.Ignored, ignoredUntrusted)
+	}
+}
+
+func TestUntrust(t *testing.T) {
+	mgr, project := newTrustTestManager(t)
+	path := filepath.Join(project, versionFileName)
+	writeTestFile(t, path, "29.4\n")
+
+	ctx := context.Background()
+	_, err := mgr.Trust(ctx, project)
+	if err != nil {
+		t.Fatalf("Trust() error = %v", err)
+	}
+
+	removed, err := mgr.Untrust(ctx, project)
+	if err != nil {
+		t.Fatalf("Untrust() error = %v", err)
+	}
+	if want := []string{path}; !reflect.DeepEqual(removed, want) {
+		t.Errorf("Untrust() = %#v, want %#v", removed, want)
+	}
+
+	ts, err := loadTrustStore(mgr.Config.Paths.Root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := ts.entries[path]; ok {
+		t.Errorf("trust store still has an entry for %s", path)
+	}
+
+	src := projectSource(t, mgr.Config, project, path)
+	if src.Ignored != ignoredUntrusted {
+		t.Errorf("Ignored = %q, want %q", src.Ignored, ignoredUntrusted)
+	}
+
+	removed, err = mgr.Untrust(ctx, project)
+	if err != nil {
+		t.Fatalf("Untrust() error = %v", err)
+	}
+	if len(removed) != 0 {
+		t.Errorf("second Untrust() = %#v, want none", removed)
+	}
+}
+
+func TestTrustNoVersionFile(t *testing.T) {
+	mgr, project := newTrustTestManager(t)
+
+	_, err := mgr.Trust(context.Background(), project)
+	if !errors.Is(err, ErrNoVersionFile) {
+		t.Fatalf("Trust() error = %v, want ErrNoVersionFile", err)
+	}
+}
+
+func TestTrustStoreSaveAndLoad(t *testing.T) {
+	root := t.TempDir()
+
+	ts, err := loadTrustStore(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts.Add("/a/.emacs-version", []byte("29.4\n"))
+	ts.Add("/b b/.tool-versions", []byte("emacs 28.2\n"))
+
+	err = ts.Save()
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := loadTrustStore(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded.entries, ts.entries) {
+		t.Errorf("loaded entries = %#v, want %#v", loaded.entries, ts.entries)
+	}
+	if !loaded.Trusted("/b b/.tool-versions", []byte("emacs 28.2\n")) {
+		t.Error("path with a space is not trusted after reload")
+	}
+	if loaded.Trusted("/a/.emacs-version", []byte("30.1\n")) {
+		t.Error("changed content is trusted")
+	}
+}
+
+func TestTrustThroughSymlink(t *testing.T) {
+	mgr, project := newTrustTestManager(t)
+	writeTestFile(t, filepath.Join(project, versionFileName), "29.4\n")
+
+	link := filepath.Join(t.TempDir(), "link")
+	err := os.Symlink(project, link)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	_, err = mgr.Trust(ctx, link)
+	if err != nil {
+		t.Fatalf("Trust() error = %v", err)
+	}
+	_, err = mgr.Trust(ctx, project)
+	if err != nil {
+		t.Fatalf("Trust() error = %v", err)
+	}
+
+	ts, err := loadTrustStore(mgr.Config.Paths.Root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ts.entries) != 1 {
+		t.Errorf("trust store has %d entries, want 1", len(ts.entries))
+	}
+
+	for _, dir := range []string{project, link} {
+		path := filepath.Join(dir, versionFileName)
+		src := projectSource(t, mgr.Config, dir, path)
+		if !src.usable() {
+			t.Errorf("source %s is not usable: %q", path, src.Ignored)
+		}
+	}
+
+	removed, err := mgr.Untrust(ctx, link)
+	if err != nil {
+		t.Fatalf("Untrust() error = %v", err)
+	}
+	if len(removed) != 1 {
+		t.Errorf("Untrust() = %#v, want one path", removed)
+	}
+
+	path := filepath.Join(project, versionFileName)
+	src := projectSource(t, mgr.Config, project, path)
+	if src.Ignored != ignoredUntrusted {
+		t.Errorf("Ignored = %q, want %q", src.Ignored, ignoredUntrusted)
+	}
+}
+
+func TestInvalidVersionIgnored(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		ignored string
+	}{
+		{
+			name:    "parent directory",
+			content: "..\n",
+			ignored: `invalid version ".."`,
+		},
+		{
+			name:    "path traversal",
+			content: "29.4 ../../bin\n",
+			ignored: `invalid version "../../bin"`,
+		},
+		{
+			name:    "backslash",
+			content: `..\bin`,
+			ignored: `invalid version "..\bin"`,
+		},
+		{
+			name:    "control character",
+			content: "29.4\x1b[0m\n",
+			ignored: "invalid version \"29.4\x1b[0m\"",
+		},
+		{name: "valid", content: "29.4 system\n", ignored: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mgr, project := newTrustTestManager(t)
+			path := filepath.Join(project, versionFileName)
+			writeTestFile(t, path, tt.content)
+
+			_, err := mgr.Trust(context.Background(), project)
+			if err != nil {
+				t.Fatalf("Trust() error = %v", err)
+			}
+
+			src := projectSource(t, mgr.Config, project, path)
+			if src.Ignored != tt.ignored {
+				t.Errorf("Ignored = %q, want %q", src.Ignored, tt.ignored)
+			}
+		})
+	}
+}
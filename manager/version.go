@@ -10,11 +10,12 @@ import (
 )
 
 type Version struct {
-	Version  string   `yaml:"version" json:"version"`
-	Current  bool     `yaml:"current" json:"current"`
-	Path     string   `yaml:"path" json:"path"`
-	BinDir   string   `yaml:"bin_dir" json:"bin_dir"`
-	Binaries []string `yaml:"binaries" json:"binaries"`
+	Version  string            `yaml:"version" json:"version"`
+	Current  bool              `yaml:"current" json:"current"`
+	Path     string            `yaml:"path" json:"path"`
+	BinDir   string            `yaml:"bin_dir" json:"bin_dir"`
+	Binaries []string          `yaml:"binaries" json:"binaries"`
+	Excluded []*ExcludedBinary `yaml:"excluded,omitempty" json:"excluded,omitempty"`
 }
 
 func (ver *Version) FindBin(name string) (string, error) {
@@ -53,7 +54,7 @@ func newVersion(
 		Version: version,
 		Path:    path,
 		BinDir:  filepath.Join(path, "bin"),
-		Current: version == conf.Current.Version,
+		Current: conf.isCurrent(version),
 	}
 
 	entries, err := os.ReadDir(ver.BinDir)
@@ -77,9 +78,20 @@ func newVersion(
 		}
 
 		// Ensure f is Regular file and executable.
-		if f.Mode().IsRegular() && f.Mode().Perm()&0111 == 0111 {
-			ver.Binaries = append(ver.Binaries, binPath)
+		if !f.Mode().IsRegular() || f.Mode().Perm()&0111 != 0111 {
+			continue
+		}
+
+		if rule := conf.excludedBy(version, entry.Name()); rule != "" {
+			ver.Excluded = append(ver.Excluded, &ExcludedBinary{
+				Name: entry.Name(),
+				Rule: rule,
+			})
+
+			continue
 		}
+
+		ver.Binaries = append(ver.Binaries, binPath)
 	}
 
 	return ver, nil
@@ -0,0 +1,162 @@
+package manager
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+const (
+	versionFileName      = ".emacs-version"
+	toolVersionsFileName = ".tool-versions"
+	toolVersionsName     = "emacs"
+)
+
+var projectVersionFileNames = []string{
+	versionFileName,
+	toolVersionsFileName,
+}
+
+const (
+	ignoredUntrusted = "untrusted"
+	ignoredDisabled  = "tool_versions is disabled"
+)
+
+// Returns the project-local version files which apply to dir, ordered from dir
+// up to the nearest directory which has any, stopping at the home directory.
+// Results are cached per directory.
+func (conf *Config) projectVersionSources(
+	dir string,
+) ([]*CurrentSource, error) {
+	if cached, ok := conf.projectSources[dir]; ok {
+		return copySources(cached), nil
+	}
+
+	trust, err := loadTrustStore(conf.Paths.Root)
+	if err != nil {
+		return nil, err
+	}
+
+	home, _ := os.UserHomeDir()
+
+	var r []*CurrentSource
+	for d := dir; ; {
+		found := false
+		for _, name := range projectVersionFileNames {
+			src, err := conf.readProjectVersion(
+				filepath.Join(d, name), trust,
+			)
+			if err != nil {
+				return nil, err
+			}
+
+			r = append(r, src)
+			found = found || src.Exists
+		}
+
+		parent := filepath.Dir(d)
+		if found || d == home || parent == d {
+			break
+		}
+		d = parent
+	}
+
+	if conf.projectSources == nil {
+		conf.projectSources = map[string][]*CurrentSource{}
+	}
+	conf.projectSources[dir] = r
+
+	return copySources(r), nil
+}
+
+func copySources(sources []*CurrentSource) []*CurrentSource {
+	r := make([]*CurrentSource, 0, len(sources))
+	for _, src := range sources {
+		c := *src
+		r = append(r, &c)
+	}
+
+	return r
+}
+
+func (conf *Config) readProjectVersion(
+	path string,
+	trust *trustStore,
+) (*CurrentSource, error) {
+	src := &CurrentSource{Type: ProjectSource, Source: path}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return src, nil
+		}
+		return nil, err
+	}
+	src.Exists = true
+
+	if filepath.Base(path) == toolVersionsFileName {
+		if !conf.ToolVersions {
+			src.Ignored = ignoredDisabled
+
+			return src, nil
+		}
+
+		src.Versions, err = parseToolVersions(b)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		src.Versions = parseVersionList(string(b))
+	}
+
+	for _, v := range src.Versions {
+		if !validVersionName(v) {
+			src.Ignored = fmt.Sprintf(`invalid version "%s"`, v)
+
+			return src, nil
+		}
+	}
+
+	if len(src.Versions) > 0 && !trust.Trusted(path, b) {
+		src.Ignored = ignoredUntrusted
+	}
+
+	return src, nil
+}
+
+// Reports if v can name a directory within the versions directory.
+func validVersionName(v string) bool {
+	return v != "." && v != ".." &&
+		!strings.ContainsAny(v, `/\`) &&
+		strings.IndexFunc(v, unicode.IsControl) < 0
+}
+
+// Parse a whitespace separated list of versions, in order of preference.
+func parseVersionList(s string) []string {
+	return strings.Fields(s)
+}
+
+// Parse the "emacs" entry of an asdf/mise compatible .tool-versions file,
+// which lists one or more versions in order of preference.
+func parseToolVersions(b []byte) ([]string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) > 1 && fields[0] == toolVersionsName {
+			return fields[1:], nil
+		}
+	}
+
+	return nil, scanner.Err()
+}
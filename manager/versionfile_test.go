@@ -0,0 +1,243 @@
+package manager
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseVersionList(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want []string
+	}{
+		{name: "empty", s: "", want: []string{}},
+		{name: "whitespace only", s: " \n\t", want: []string{}},
+		{name: "single", s: "29.4\n", want: []string{"29.4"}},
+		{
+			name: "multiple",
+			s:    "29.4 28.2\n",
+			want: []string{"29.4", "28.2"},
+		},
+		{
+			name: "multiple lines",
+			s:    "29.4\n  28.2\tsystem\n",
+			want: []string{"29.4", "28.2", "system"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseVersionList(tt.s)
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseVersionList() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseToolVersions(t *testing.T) {
+	tests := []struct {
+		name string
+		b    string
+		want []string
+	}{
+		{name: "empty", b: "", want: nil},
+		{
+			name: "single",
+			b:    "emacs 29.4\n",
+			want: []string{"29.4"},
+		},
+		{
+			name: "multiple versions",
+			b:    "emacs 29.4 28.2 system\n",
+			want: []string{"29.4", "28.2", "system"},
+		},
+		{
+			name: "among other tools",
+			b:    "nodejs 20.1.0\nemacs 29.4\nruby 3.3.0\n",
+			want: []string{"29.4"},
+		},
+		{
+			name: "missing emacs line",
+			b:    "nodejs 20.1.0\nruby 3.3.0\n",
+			want: nil,
+		},
+		{
+			name: "emacs without version",
+			b:    "emacs\n",
+			want: nil,
+		},
+		{
+			name: "trailing comment",
+			b:    "emacs 29.4 # latest stable\n",
+			want: []string{"29.4"},
+		},
+		{
+			name: "commented out line",
+			b:    "# emacs 30.1\nemacs 29.4\n",
+			want: []string{"29.4"},
+		},
+		{
+			name: "tool name prefix",
+			b:    "emacs-plus 30.1\nemacs 29.4\n",
+			want: []string{"29.4"},
+		},
+		{
+			name: "extra whitespace",
+			b:    "  emacs\t29.4   28.2  \n",
+			want: []string{"29.4", "28.2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseToolVersions([]byte(tt.b))
+			if err != nil {
+				t.Fatalf("parseToolVersions() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseToolVersions() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPopulateCurrentRemovedWorkingDirectory(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, currentFileName), "29.4\n")
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := os.Chdir(wd); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	dir := filepath.Join(t.TempDir(), "removed")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv(versionEnvName, "")
+	os.Unsetenv(versionEnvName)
+
+	conf := &Config{Paths: PathsConfig{Root: root}}
+	err = conf.PopulateCurrent()
+	if err != nil {
+		t.Fatalf("PopulateCurrent() error = %v", err)
+	}
+
+	if conf.Current.Version != "29.4" {
+		t.Errorf("Current.Version = %q, want %q", conf.Current.Version, "29.4")
+	}
+	for _, src := range conf.Current.Sources {
+		if src.Type == ProjectSource {
+			t.Errorf("unexpected project source %s", src.Source)
+		}
+	}
+}
+
+func TestProjectVersionSourcesStop(t *testing.T) {
+	top := t.TempDir()
+	for _, dir := range []string{"a/b", "home/b"} {
+		err := os.MkdirAll(filepath.Join(top, dir), 0o755)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeTestFile(t, filepath.Join(top, versionFileName), "28.2\n")
+	writeTestFile(
+		t, filepath.Join(top, "a", toolVersionsFileName), "emacs 29.4\n",
+	)
+	t.Setenv("HOME", filepath.Join(top, "home"))
+
+	tests := []struct {
+		name string
+		dir  string
+		want []string
+	}{
+		{
+			name: "nearest version file",
+			dir:  "a/b",
+			want: []string{
+				"a/b/" + versionFileName,
+				"a/b/" + toolVersionsFileName,
+				"a/" + versionFileName,
+				"a/" + toolVersionsFileName,
+			},
+		},
+		{
+			name: "home directory",
+			dir:  "home/b",
+			want: []string{
+				"home/b/" + versionFileName,
+				"home/b/" + toolVersionsFileName,
+				"home/" + versionFileName,
+				"home/" + toolVersionsFileName,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := &Config{
+				Paths:        PathsConfig{Root: t.TempDir()},
+				ToolVersions: true,
+			}
+
+			sources, err := conf.projectVersionSources(
+				filepath.Join(top, tt.dir),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for _, src := range sources {
+				rel, err := filepath.Rel(top, src.Source)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, filepath.ToSlash(rel))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sources = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProjectVersionSourcesCached(t *testing.T) {
+	dir := t.TempDir()
+	conf := &Config{Paths: PathsConfig{Root: t.TempDir()}}
+
+	sources, err := conf.projectVersionSources(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sources[0].Selected = true
+
+	writeTestFile(t, filepath.Join(dir, versionFileName), "29.4\n")
+
+	sources, err = conf.projectVersionSources(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sources[0].Exists {
+		t.Error("version file found, want cached result")
+	}
+	if sources[0].Selected {
+		t.Error("cached source was modified")
+	}
+}